@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Options struct {
+	Format  Format
+	OnEntry func(name string, size uint64)
+}
+
+// entryWriter adds objects to an archive being streamed to a writer.
+type entryWriter interface {
+	WriteEntry(name string, md *types.ObjectMetadata, r io.Reader) error
+	Close() error
+}
+
+// Create streams every object under prefix into an archive written to dst,
+// entries being named after the object path relative to prefix. Nothing is
+// staged locally and dst is aborted on failure.
+func Create(ctx context.Context, store *objstr.ObjectStore, prefix *url.URL, dst *url.URL, opts Options) error {
+	prefix, err := store.ResolvePrefix(prefix)
+
+	if err != nil {
+		return err
+	}
+
+	objects, err := store.ListPrefix(ctx, prefix)
+
+	if err != nil {
+		return err
+	}
+
+	w, err := store.Writer(ctx, dst)
+
+	if err != nil {
+		return err
+	}
+
+	if err := writeArchive(ctx, store, prefix, objects, w, opts); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
+
+func writeArchive(ctx context.Context, store *objstr.ObjectStore, prefix *url.URL, objects []*types.Object, w io.Writer, opts Options) error {
+	aw, err := newEntryWriter(w, opts.Format)
+
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		var name = strings.TrimPrefix(strings.TrimPrefix(obj.URL.Path, prefix.Path), "/")
+
+		if len(name) == 0 || strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		r, err := store.Reader(ctx, obj.URL)
+
+		if err != nil {
+			return err
+		}
+
+		err = aw.WriteEntry(name, obj.Metadata, r)
+		r.Close()
+
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", obj.URL.String(), err)
+		}
+
+		if opts.OnEntry != nil {
+			opts.OnEntry(name, obj.Metadata.Size)
+		}
+	}
+
+	return aw.Close()
+}
+
+func newEntryWriter(w io.Writer, format Format) (entryWriter, error) {
+	switch format {
+	case FormatTar:
+		return &tarWriter{tw: tar.NewWriter(w)}, nil
+
+	case FormatTarGz:
+		var gw = gzip.NewWriter(w)
+		return &tarWriter{tw: tar.NewWriter(gw), compressor: gw}, nil
+
+	case FormatTarZst:
+		zw, err := zstd.NewWriter(w)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &tarWriter{tw: tar.NewWriter(zw), compressor: zw}, nil
+
+	case FormatZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown archive format: %s", format)
+	}
+}
+
+type tarWriter struct {
+	tw         *tar.Writer
+	compressor io.WriteCloser
+}
+
+func (w *tarWriter) WriteEntry(name string, md *types.ObjectMetadata, r io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(md.Size),
+		Mode:     0644,
+		ModTime:  md.ModificationDate,
+	}); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(w.tw, r)
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+
+	return nil
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) WriteEntry(name string, md *types.ObjectMetadata, r io.Reader) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Deflate,
+		Modified:           md.ModificationDate,
+		UncompressedSize64: md.Size,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
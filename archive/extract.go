@@ -0,0 +1,170 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Extract writes every regular file of the archive at src as an object under
+// dstPrefix. Tar archives are streamed; zip archives are read through the
+// backend's ReaderAt since their directory sits at the end of the object.
+func Extract(ctx context.Context, store *objstr.ObjectStore, src *url.URL, dstPrefix *url.URL, opts Options) error {
+	if opts.Format == FormatZip {
+		return extractZip(ctx, store, src, dstPrefix, opts)
+	}
+
+	r, err := store.Reader(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	var decompressed io.Reader = r
+
+	switch opts.Format {
+	case FormatTar:
+
+	case FormatTarGz:
+		gr, err := gzip.NewReader(r)
+
+		if err != nil {
+			return err
+		}
+
+		defer gr.Close()
+		decompressed = gr
+
+	case FormatTarZst:
+		zr, err := zstd.NewReader(r)
+
+		if err != nil {
+			return err
+		}
+
+		defer zr.Close()
+		decompressed = zr
+
+	default:
+		return fmt.Errorf("unknown archive format: %s", opts.Format)
+	}
+
+	var tr = tar.NewReader(decompressed)
+
+	for {
+		hdr, err := tr.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := extractEntry(ctx, store, dstPrefix, hdr.Name, tr); err != nil {
+			return err
+		}
+
+		if opts.OnEntry != nil {
+			opts.OnEntry(hdr.Name, uint64(hdr.Size))
+		}
+	}
+}
+
+func extractZip(ctx context.Context, store *objstr.ObjectStore, src *url.URL, dstPrefix *url.URL, opts Options) error {
+	md, err := store.ReadMetadata(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	ra, err := store.ReaderAt(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	defer ra.Close()
+
+	zr, err := zip.NewReader(ra, int64(md.Size))
+
+	if err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		if f.Mode().IsDir() || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		fr, err := f.Open()
+
+		if err != nil {
+			return err
+		}
+
+		err = extractEntry(ctx, store, dstPrefix, f.Name, fr)
+		fr.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if opts.OnEntry != nil {
+			opts.OnEntry(f.Name, f.UncompressedSize64)
+		}
+	}
+
+	return nil
+}
+
+func extractEntry(ctx context.Context, store *objstr.ObjectStore, dstPrefix *url.URL, name string, r io.Reader) error {
+	dst, err := entryURL(dstPrefix, name)
+
+	if err != nil {
+		return err
+	}
+
+	w, err := store.Writer(ctx, dst)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+
+	return w.Close()
+}
+
+// entryURL cleans the entry name as a rooted path so that ".." elements can't
+// escape dstPrefix.
+func entryURL(dstPrefix *url.URL, name string) (*url.URL, error) {
+	var clean = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+
+	if clean == "/" {
+		return nil, fmt.Errorf("invalid archive entry name: %s", name)
+	}
+
+	var u = *dstPrefix
+	u.Path = strings.TrimSuffix(dstPrefix.Path, "/") + clean
+	return &u, nil
+}
@@ -0,0 +1,49 @@
+package archive
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatTar    Format = "tar"
+	FormatTarGz  Format = "tar.gz"
+	FormatTarZst Format = "tar.zst"
+	FormatZip    Format = "zip"
+)
+
+var Formats = []Format{FormatTar, FormatTarGz, FormatTarZst, FormatZip}
+
+var extensions = map[string]Format{
+	".tar":     FormatTar,
+	".tar.gz":  FormatTarGz,
+	".tgz":     FormatTarGz,
+	".tar.zst": FormatTarZst,
+	".tzst":    FormatTarZst,
+	".zip":     FormatZip,
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown archive format: %s", s)
+}
+
+// FormatFromPath guesses the format from the extension of p.
+func FormatFromPath(p string) (Format, error) {
+	var lower = strings.ToLower(p)
+
+	for ext, f := range extensions {
+		if strings.HasSuffix(lower, ext) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("cannot guess archive format of %s", p)
+}
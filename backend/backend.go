@@ -12,7 +12,7 @@ type Backend interface {
 	ReadMetadata(context.Context, *url.URL) (*types.ObjectMetadata, error)
 	Reader(context.Context, *url.URL) (types.Reader, error)
 	ReaderAt(context.Context, *url.URL) (types.ReaderAt, error)
-	Writer(context.Context, *url.URL) (types.Writer, error)
+	Writer(context.Context, *url.URL, ...types.WriteOption) (types.Writer, error)
 	Delete(context.Context, *url.URL) error
 	Close() error
 }
@@ -21,3 +21,16 @@ type MoveableBackend interface {
 	Backend
 	Move(context.Context, *url.URL, *url.URL) error
 }
+
+type ResumableBackend interface {
+	Backend
+	ResumableUpload(context.Context, *url.URL, types.ReaderAt, *types.Object, string) error
+}
+
+// PathResolver is implemented by backends whose listings return object paths
+// in another form than the requested URL, like the absolute paths returned
+// by the fs backend for a relative URL.
+type PathResolver interface {
+	Backend
+	ResolvePath(*url.URL) (*url.URL, error)
+}
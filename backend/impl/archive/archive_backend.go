@@ -0,0 +1,337 @@
+package archive
+
+import (
+	"archive/tar"
+	"context"
+	stderr "errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/agnosticeng/objstr/backend"
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	FormatZip = "zip"
+	FormatTar = "tar"
+)
+
+type ArchiveBackendConfig struct {
+	Format string
+}
+
+// ArchiveBackend exposes the entries of zip and tar objects stored on
+// another backend as read-only objects. Indexes are cached per archive and
+// rebuilt when the archive's metadata changes; a replaced index may still be
+// in use by open readers, so its handle is only closed with the backend.
+type ArchiveBackend struct {
+	conf        ArchiveBackendConfig
+	inner       backend.Backend
+	builds      singleflight.Group
+	indexesLock sync.Mutex
+	indexes     map[string]*index
+	retired     []*index
+}
+
+func NewArchiveBackend(ctx context.Context, conf ArchiveBackendConfig, inner backend.Backend) (*ArchiveBackend, error) {
+	if conf.Format != FormatZip && conf.Format != FormatTar {
+		return nil, fmt.Errorf("unknown archive format: %s", conf.Format)
+	}
+
+	return &ArchiveBackend{
+		conf:    conf,
+		inner:   inner,
+		indexes: make(map[string]*index),
+	}, nil
+}
+
+func (be *ArchiveBackend) getIndex(ctx context.Context, l *location) (*index, error) {
+	md, err := be.inner.ReadMetadata(ctx, l.Archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var key = l.Archive.String()
+
+	if idx := be.cachedIndex(key, md); idx != nil {
+		return idx, nil
+	}
+
+	// builds are deduplicated per archive, without holding indexesLock, so
+	// that indexing a large archive doesn't block the other ones
+	v, err, _ := be.builds.Do(key, func() (any, error) {
+		if idx := be.cachedIndex(key, md); idx != nil {
+			return idx, nil
+		}
+
+		var (
+			idx *index
+			err error
+		)
+
+		switch be.conf.Format {
+		case FormatZip:
+			idx, err = buildZipIndex(ctx, be.inner, l, md)
+		default:
+			idx, err = buildTarIndex(ctx, be.inner, l, md)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", l.Archive.String(), err)
+		}
+
+		be.indexesLock.Lock()
+		defer be.indexesLock.Unlock()
+
+		if prev, found := be.indexes[key]; found {
+			be.retired = append(be.retired, prev)
+		}
+
+		be.indexes[key] = idx
+		return idx, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*index), nil
+}
+
+func (be *ArchiveBackend) cachedIndex(key string, md *types.ObjectMetadata) *index {
+	be.indexesLock.Lock()
+	defer be.indexesLock.Unlock()
+
+	if idx, found := be.indexes[key]; found && idx.matches(md) {
+		return idx
+	}
+
+	return nil
+}
+
+func (be *ArchiveBackend) getEntry(ctx context.Context, u *url.URL) (*location, *index, *entry, error) {
+	l, err := parseLocation(u)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	idx, err := be.getIndex(ctx, l)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	e, found := idx.byName[l.Path]
+
+	if !found {
+		return nil, nil, nil, errors.ErrObjectNotFound
+	}
+
+	return l, idx, e, nil
+}
+
+func (be *ArchiveBackend) ListPrefix(ctx context.Context, u *url.URL, optFuncs ...types.ListOption) ([]*types.Object, error) {
+	var opts = types.NewListOptions(optFuncs...)
+
+	l, err := parseLocation(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := be.getIndex(ctx, l)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*types.Object
+
+	for _, e := range idx.entries {
+		if !strings.HasPrefix(e.Name, l.Path) {
+			continue
+		}
+
+		var obj = types.Object{
+			URL:      l.entryURL(u, e.Name),
+			Metadata: e.metadata(),
+		}
+
+		if len(opts.StartAfter) > 0 && strings.Compare(obj.URL.String(), opts.StartAfter) <= 0 {
+			continue
+		}
+
+		res = append(res, &obj)
+	}
+
+	return types.GroupByDelimiter(l.entryPath(l.Path), res, opts.Delimiter), nil
+}
+
+func (be *ArchiveBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
+	_, _, e, err := be.getEntry(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.metadata(), nil
+}
+
+func (be *ArchiveBackend) Reader(ctx context.Context, u *url.URL) (types.Reader, error) {
+	l, idx, e, err := be.getEntry(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if e.zip != nil {
+		return e.zip.Open()
+	}
+
+	if !idx.compressed {
+		ra, err := be.inner.ReaderAt(ctx, l.Archive)
+
+		if err == nil {
+			return &sectionReader{SectionReader: io.NewSectionReader(ra, e.Offset, int64(e.Size)), closer: ra}, nil
+		}
+
+		if !stderr.Is(err, stderr.ErrUnsupported) {
+			return nil, err
+		}
+	}
+
+	return be.streamTarEntry(ctx, l, e)
+}
+
+// streamTarEntry decompresses the archive up to the entry; used when the
+// wrapped backend can't read at an offset or the archive is compressed.
+func (be *ArchiveBackend) streamTarEntry(ctx context.Context, l *location, e *entry) (types.Reader, error) {
+	r, err := be.inner.Reader(ctx, l.Archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tr, _, closer, err := newTarReader(r)
+
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	for {
+		hdr, err := tr.Next()
+
+		if err != nil {
+			closer()
+			r.Close()
+
+			if err == io.EOF {
+				return nil, errors.ErrObjectNotFound
+			}
+
+			return nil, err
+		}
+
+		if hdr.Typeflag == tar.TypeReg && entryName(hdr.Name) == e.Name {
+			return &tarEntryReader{Reader: tr, close: func() error {
+				closer()
+				return r.Close()
+			}}, nil
+		}
+	}
+}
+
+func (be *ArchiveBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt, error) {
+	l, idx, e, err := be.getEntry(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Offset < 0 {
+		return nil, stderr.ErrUnsupported
+	}
+
+	if idx.ra != nil {
+		return &sectionReader{SectionReader: io.NewSectionReader(idx.ra, e.Offset, int64(e.Size))}, nil
+	}
+
+	ra, err := be.inner.ReaderAt(ctx, l.Archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &sectionReader{SectionReader: io.NewSectionReader(ra, e.Offset, int64(e.Size)), closer: ra}, nil
+}
+
+func (be *ArchiveBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
+	return nil, stderr.ErrUnsupported
+}
+
+func (be *ArchiveBackend) Delete(ctx context.Context, u *url.URL) error {
+	return stderr.ErrUnsupported
+}
+
+func (be *ArchiveBackend) Close() error {
+	be.indexesLock.Lock()
+	defer be.indexesLock.Unlock()
+
+	var res *multierror.Error
+
+	for key, idx := range be.indexes {
+		if err := idx.Close(); err != nil {
+			res = multierror.Append(res, err)
+		}
+
+		delete(be.indexes, key)
+	}
+
+	for _, idx := range be.retired {
+		if err := idx.Close(); err != nil {
+			res = multierror.Append(res, err)
+		}
+	}
+
+	be.retired = nil
+	return res.ErrorOrNil()
+}
+
+func (e *entry) metadata() *types.ObjectMetadata {
+	return &types.ObjectMetadata{
+		Size:             e.Size,
+		ModificationDate: e.ModificationDate,
+		ETag:             e.ETag,
+	}
+}
+
+type sectionReader struct {
+	*io.SectionReader
+	closer io.Closer
+}
+
+func (r *sectionReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+
+	return nil
+}
+
+type tarEntryReader struct {
+	io.Reader
+	close func() error
+}
+
+func (r *tarEntryReader) Close() error {
+	return r.close()
+}
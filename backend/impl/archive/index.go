@@ -0,0 +1,225 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr/backend"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+type entry struct {
+	Name             string
+	Size             uint64
+	ModificationDate time.Time
+	ETag             string
+	// Offset is the position of the data of an uncompressed entry in the
+	// archive object, or -1 when it can only be reached by decompression.
+	Offset int64
+	zip    *zip.File
+}
+
+// index lists the entries of an archive object as it was when the index was
+// built; md is used to detect that the object changed since.
+type index struct {
+	md         *types.ObjectMetadata
+	entries    []*entry
+	byName     map[string]*entry
+	compressed bool
+	ra         types.ReaderAt
+}
+
+func newIndex(md *types.ObjectMetadata, entries []*entry, compressed bool, ra types.ReaderAt) *index {
+	var byName = make(map[string]*entry, len(entries))
+
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return &index{md: md, entries: entries, byName: byName, compressed: compressed, ra: ra}
+}
+
+// entryName normalizes names such as "./dir/file" or "/dir/file" to the
+// "dir/file" form used as key inside the archive.
+func entryName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (idx *index) matches(md *types.ObjectMetadata) bool {
+	return idx.md.Size == md.Size &&
+		idx.md.ETag == md.ETag &&
+		idx.md.ModificationDate.Equal(md.ModificationDate)
+}
+
+func (idx *index) Close() error {
+	if idx.ra != nil {
+		return idx.ra.Close()
+	}
+
+	return nil
+}
+
+// buildZipIndex reads the central directory of the archive through the
+// wrapped backend's ReaderAt, the handle being kept open to read entries.
+// Since the handle outlives the request building the index, and some
+// ReaderAt implementations keep the context they were opened with, it's
+// opened with a context that isn't cancelled with the request.
+func buildZipIndex(ctx context.Context, be backend.Backend, l *location, md *types.ObjectMetadata) (*index, error) {
+	ra, err := be.ReaderAt(context.WithoutCancel(ctx), l.Archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(ra, int64(md.Size))
+
+	if err != nil {
+		ra.Close()
+		return nil, err
+	}
+
+	var entries []*entry
+
+	for _, f := range zr.File {
+		if f.Mode().IsDir() || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		var e = entry{
+			Name:             entryName(f.Name),
+			Size:             f.UncompressedSize64,
+			ModificationDate: f.Modified.UTC(),
+			ETag:             fmt.Sprintf("%08x", f.CRC32),
+			Offset:           -1,
+			zip:              f,
+		}
+
+		if f.Method == zip.Store {
+			if offset, err := f.DataOffset(); err == nil {
+				e.Offset = offset
+			}
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return newIndex(md, entries, false, ra), nil
+}
+
+// buildTarIndex streams the whole archive once. Data offsets are recorded for
+// uncompressed archives so that entries can later be read directly.
+func buildTarIndex(ctx context.Context, be backend.Backend, l *location, md *types.ObjectMetadata) (*index, error) {
+	r, err := be.Reader(ctx, l.Archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	var cr = countingReader{r: r}
+
+	tr, compressed, closer, err := newTarReader(&cr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer closer()
+
+	var entries []*entry
+
+	for {
+		hdr, err := tr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var e = entry{
+			Name:             entryName(hdr.Name),
+			Size:             uint64(hdr.Size),
+			ModificationDate: hdr.ModTime.UTC(),
+			Offset:           -1,
+		}
+
+		if !compressed {
+			e.Offset = cr.n
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return newIndex(md, entries, compressed, nil), nil
+}
+
+// newTarReader detects gzip and zstd compression from the magic number of
+// the stream. The magic is read without buffering ahead so that, for plain
+// archives, the bytes consumed from r match the position in the archive.
+func newTarReader(r io.Reader) (*tar.Reader, bool, func(), error) {
+	var magic = make([]byte, 4)
+
+	n, err := io.ReadFull(r, magic)
+
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, nil, err
+	}
+
+	var full = io.MultiReader(bytes.NewReader(magic[:n]), r)
+
+	switch {
+	case bytes.HasPrefix(magic[:n], []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(full)
+
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		return tar.NewReader(gr), true, func() { gr.Close() }, nil
+
+	case bytes.HasPrefix(magic[:n], []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(full)
+
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		return tar.NewReader(zr), true, zr.Close, nil
+
+	default:
+		return tar.NewReader(full), false, func() {}, nil
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,63 @@
+package archive
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// zip+s3://bucket/data.zip!/inner/file.csv
+// tar+file:///tmp/data.tar.gz!/inner/
+
+const separator = "!/"
+
+type location struct {
+	Archive *url.URL
+	Path    string
+}
+
+// parseLocation splits an archive URL into the URL of the archive object on
+// the wrapped backend and the path of an entry inside it; an URL without
+// separator designates the root of the archive.
+func parseLocation(u *url.URL) (*location, error) {
+	_, scheme, found := strings.Cut(u.Scheme, "+")
+
+	if !found || len(scheme) == 0 {
+		return nil, fmt.Errorf("invalid archive url scheme: %s", u.Scheme)
+	}
+
+	var (
+		archive           = *u
+		archivePath, p, _ = strings.Cut(u.Path, separator)
+	)
+
+	archive.Scheme = scheme
+	archive.Path = strings.TrimSuffix(archivePath, "!")
+	archive.RawPath = ""
+
+	if len(archive.Path) == 0 || strings.HasSuffix(archive.Path, "/") {
+		return nil, fmt.Errorf("invalid archive url: %s", u.String())
+	}
+
+	return &location{Archive: &archive, Path: p}, nil
+}
+
+// entryPath returns the path of an URL designating the given entry of the
+// archive.
+func (l *location) entryPath(name string) string {
+	return l.Archive.Path + separator + name
+}
+
+// entryURL keeps the separator unescaped so that the URL can be printed and
+// parsed back.
+func (l *location) entryURL(u *url.URL, name string) *url.URL {
+	var res = url.URL{
+		Scheme: u.Scheme,
+		User:   u.User,
+		Host:   u.Host,
+		Path:   l.entryPath(name),
+	}
+
+	res.RawPath = strings.ReplaceAll(res.EscapedPath(), "%21", "!")
+	return &res
+}
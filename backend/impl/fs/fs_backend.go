@@ -29,6 +29,31 @@ func (be *FSBackend) validateURL(u *url.URL) error {
 	return nil
 }
 
+// ResolvePath returns the absolute path form used by ListPrefix, keeping
+// the trailing "/" of a directory prefix; an empty path is the working
+// directory.
+func (be *FSBackend) ResolvePath(u *url.URL) (*url.URL, error) {
+	var p = filepath.Join(u.Host, u.Path)
+
+	absPath, err := filepath.Abs(p)
+
+	if err != nil {
+		return nil, err
+	}
+
+	absPath = filepath.ToSlash(absPath)
+
+	if (len(p) == 0 || strings.HasSuffix(u.Path, "/")) && absPath != "/" {
+		absPath += "/"
+	}
+
+	var res = *u
+	res.Host = ""
+	res.Path = absPath
+	res.RawPath = ""
+	return &res, nil
+}
+
 func (be *FSBackend) ListPrefix(ctx context.Context, u *url.URL, optFuncs ...types.ListOption) ([]*types.Object, error) {
 	var (
 		opts       = types.NewListOptions(optFuncs...)
@@ -43,6 +68,10 @@ func (be *FSBackend) ListPrefix(ctx context.Context, u *url.URL, optFuncs ...typ
 		return nil, err
 	}
 
+	if strings.HasSuffix(u.Path, "/") && absPathPrefix != "/" {
+		absPathPrefix = absPathPrefix + "/"
+	}
+
 	absPathPrefixSegments := strings.Split(absPathPrefix, "/")
 
 	for i := len(absPathPrefixSegments); i >= 0; i-- {
@@ -64,7 +93,7 @@ func (be *FSBackend) ListPrefix(ctx context.Context, u *url.URL, optFuncs ...typ
 			return err
 		}
 
-		if !info.Mode().IsRegular() {
+		if !info.Mode().IsRegular() || isTempFile(info.Name()) {
 			return nil
 		}
 
@@ -97,7 +126,7 @@ func (be *FSBackend) ListPrefix(ctx context.Context, u *url.URL, optFuncs ...typ
 		return nil, err
 	}
 
-	return res, nil
+	return types.GroupByDelimiter(absPathPrefix, res, opts.Delimiter), nil
 }
 
 func (be *FSBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
@@ -115,6 +144,10 @@ func (be *FSBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.Objec
 		return nil, err
 	}
 
+	if !stat.Mode().IsRegular() {
+		return nil, errors.ErrObjectNotFound
+	}
+
 	return &types.ObjectMetadata{
 		Size:             uint64(stat.Size()),
 		ModificationDate: stat.ModTime(),
@@ -149,7 +182,7 @@ func (be *FSBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt,
 	return f, nil
 }
 
-func (be *FSBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *FSBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	if err := be.validateURL(u); err != nil {
 		return nil, err
 	}
@@ -160,7 +193,7 @@ func (be *FSBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, erro
 		return nil, err
 	}
 
-	return os.Create(path)
+	return NewFSWriter(path, types.NewWriteOptions(optFuncs...).IfAbsent)
 }
 
 func (be *FSBackend) Delete(ctx context.Context, u *url.URL) error {
@@ -0,0 +1,84 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/hashicorp/go-multierror"
+)
+
+// Writers stage the content next to the destination, in a file named after
+// it that listings skip.
+var tempFilePattern = regexp.MustCompile(`^\..+\.[0-9]+\.tmp$`)
+
+func isTempFile(name string) bool {
+	return tempFilePattern.MatchString(name)
+}
+
+type FSWriter struct {
+	path     string
+	ifAbsent bool
+	f        *os.File
+}
+
+func NewFSWriter(path string, ifAbsent bool) (*FSWriter, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &FSWriter{path: path, ifAbsent: ifAbsent, f: f}, nil
+}
+
+func (w *FSWriter) Write(data []byte) (int, error) {
+	return w.f.Write(data)
+}
+
+func (w *FSWriter) Close() error {
+	if err := w.f.Close(); err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+
+	if w.ifAbsent {
+		// linking fails when the destination exists, unlike renaming
+		var err = os.Link(w.f.Name(), w.path)
+		os.Remove(w.f.Name())
+
+		if os.IsExist(err) {
+			return errors.ErrObjectAlreadyExists
+		}
+
+		return err
+	}
+
+	if err := os.Rename(w.f.Name(), w.path); err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (w *FSWriter) Abort() error {
+	var res *multierror.Error
+
+	if err := w.f.Close(); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	if err := os.Remove(w.f.Name()); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	return res.ErrorOrNil()
+}
@@ -112,6 +112,8 @@ func (be *GitBackend) cloneCommit(ctx context.Context, fl *fileLocation) (*objec
 }
 
 func (be *GitBackend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...types.ListOption) ([]*types.Object, error) {
+	var opts = types.NewListOptions(optFunc...)
+
 	fl, err := parseFileLocation(u.String(), be.matchers)
 
 	if err != nil {
@@ -163,7 +165,7 @@ func (be *GitBackend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...typ
 		res = append(res, &obj)
 	}
 
-	return res, nil
+	return types.GroupByDelimiter(u.Path, res, opts.Delimiter), nil
 }
 
 func (be *GitBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
@@ -217,7 +219,7 @@ func (be *GitBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt,
 	return nil, stderr.ErrUnsupported
 }
 
-func (be *GitBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *GitBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	return nil, stderr.ErrUnsupported
 }
 
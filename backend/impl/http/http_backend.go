@@ -71,7 +71,7 @@ func (be *HTTPBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt
 	return nil, stderr.ErrUnsupported
 }
 
-func (be *HTTPBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *HTTPBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	return nil, stderr.ErrUnsupported
 
 }
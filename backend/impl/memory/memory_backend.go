@@ -2,11 +2,12 @@ package memory
 
 import (
 	"context"
-	stderr "errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/agnosticeng/objstr/errors"
 	"github.com/agnosticeng/objstr/types"
@@ -33,8 +34,68 @@ func (be *MemoryBackend) validateURL(u *url.URL) error {
 	return nil
 }
 
+func (be *MemoryBackend) path(u *url.URL) string {
+	return filepath.Join("/", u.Host, u.Path)
+}
+
 func (be *MemoryBackend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...types.ListOption) ([]*types.Object, error) {
-	return nil, stderr.ErrUnsupported
+	var (
+		opts       = types.NewListOptions(optFunc...)
+		res        []*types.Object
+		root       = filepath.Join("/", u.Host)
+		pathPrefix = filepath.Join("/", u.Path)
+	)
+
+	if strings.HasSuffix(u.Path, "/") && pathPrefix != "/" {
+		pathPrefix = pathPrefix + "/"
+	}
+
+	err := afero.Walk(be.fs, root, func(path string, info fs.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() || isTempFile(info.Name()) {
+			return nil
+		}
+
+		var objUrl = &url.URL{
+			Scheme: u.Scheme,
+			User:   u.User,
+			Host:   u.Host,
+			Path:   filepath.Join("/", strings.TrimPrefix(path, root)),
+		}
+
+		if !strings.HasPrefix(objUrl.Path, pathPrefix) {
+			return nil
+		}
+
+		if len(opts.StartAfter) > 0 {
+			if strings.Compare(objUrl.String(), opts.StartAfter) <= 0 {
+				return nil
+			}
+		}
+
+		res = append(res, &types.Object{
+			URL: objUrl,
+			Metadata: &types.ObjectMetadata{
+				Size:             uint64(info.Size()),
+				ModificationDate: info.ModTime(),
+			},
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return types.GroupByDelimiter(pathPrefix, res, opts.Delimiter), nil
 }
 
 func (be *MemoryBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
@@ -42,7 +103,7 @@ func (be *MemoryBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.O
 		return nil, err
 	}
 
-	stat, err := be.fs.Stat(filepath.Join(u.Host, u.Path))
+	stat, err := be.fs.Stat(be.path(u))
 
 	if os.IsNotExist(err) {
 		return nil, errors.ErrObjectNotFound
@@ -52,8 +113,13 @@ func (be *MemoryBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.O
 		return nil, err
 	}
 
+	if !stat.Mode().IsRegular() {
+		return nil, errors.ErrObjectNotFound
+	}
+
 	return &types.ObjectMetadata{
-		Size: uint64(stat.Size()),
+		Size:             uint64(stat.Size()),
+		ModificationDate: stat.ModTime(),
 	}, nil
 }
 
@@ -62,7 +128,7 @@ func (be *MemoryBackend) Reader(ctx context.Context, u *url.URL) (types.Reader,
 		return nil, err
 	}
 
-	f, err := be.fs.Open(filepath.Join(u.Host, u.Path))
+	f, err := be.fs.Open(be.path(u))
 
 	if os.IsNotExist(err) {
 		return nil, errors.ErrObjectNotFound
@@ -76,7 +142,7 @@ func (be *MemoryBackend) ReaderAt(ctx context.Context, u *url.URL) (types.Reader
 		return nil, err
 	}
 
-	f, err := be.fs.Open(filepath.Join(u.Host, u.Path))
+	f, err := be.fs.Open(be.path(u))
 
 	if os.IsNotExist(err) {
 		return nil, errors.ErrObjectNotFound
@@ -85,18 +151,18 @@ func (be *MemoryBackend) ReaderAt(ctx context.Context, u *url.URL) (types.Reader
 	return f, nil
 }
 
-func (be *MemoryBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *MemoryBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	if err := be.validateURL(u); err != nil {
 		return nil, err
 	}
 
-	path := filepath.Join(u.Host, u.Path)
+	path := be.path(u)
 
 	if err := be.fs.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
 		return nil, err
 	}
 
-	return be.fs.Create(path)
+	return NewMemoryWriter(be.fs, path, types.NewWriteOptions(optFuncs...).IfAbsent)
 }
 
 func (be *MemoryBackend) Delete(ctx context.Context, u *url.URL) error {
@@ -104,7 +170,7 @@ func (be *MemoryBackend) Delete(ctx context.Context, u *url.URL) error {
 		return err
 	}
 
-	return be.fs.Remove(filepath.Join(u.Host, u.Path))
+	return be.fs.Remove(be.path(u))
 }
 
 func (be *MemoryBackend) Close() error {
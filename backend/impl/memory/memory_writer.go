@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"path/filepath"
+	"regexp"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+// Writers stage the content next to the destination, in a file named after
+// it that listings skip.
+var tempFilePattern = regexp.MustCompile(`^\..+\.[0-9]+\.tmp$`)
+
+func isTempFile(name string) bool {
+	return tempFilePattern.MatchString(name)
+}
+
+type MemoryWriter struct {
+	fs       afero.Fs
+	path     string
+	ifAbsent bool
+	f        afero.File
+}
+
+func NewMemoryWriter(fs afero.Fs, path string, ifAbsent bool) (*MemoryWriter, error) {
+	f, err := afero.TempFile(fs, filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemoryWriter{fs: fs, path: path, ifAbsent: ifAbsent, f: f}, nil
+}
+
+func (w *MemoryWriter) Write(data []byte) (int, error) {
+	return w.f.Write(data)
+}
+
+func (w *MemoryWriter) Close() error {
+	if err := w.f.Close(); err != nil {
+		w.fs.Remove(w.f.Name())
+		return err
+	}
+
+	if w.ifAbsent {
+		if exists, err := afero.Exists(w.fs, w.path); err != nil || exists {
+			w.fs.Remove(w.f.Name())
+
+			if err != nil {
+				return err
+			}
+
+			return errors.ErrObjectAlreadyExists
+		}
+	}
+
+	if err := w.fs.Rename(w.f.Name(), w.path); err != nil {
+		w.fs.Remove(w.f.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (w *MemoryWriter) Abort() error {
+	var res *multierror.Error
+
+	if err := w.f.Close(); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	if err := w.fs.Remove(w.f.Name()); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	return res.ErrorOrNil()
+}
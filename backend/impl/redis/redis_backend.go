@@ -2,11 +2,18 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	stderr "errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/url"
+	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/agnosticeng/objstr/errors"
 	"github.com/agnosticeng/objstr/types"
@@ -14,106 +21,480 @@ import (
 	slogctx "github.com/veqryn/slog-context"
 )
 
+const defaultChunkSize = 1024 * 1024
+
 type RedisBackendConfig struct {
 	Dsn string
+	// Addresses overrides the address of the DSN with several nodes: seed
+	// nodes of a cluster, which is detected automatically, or sentinels
+	// when Sentinel.MasterSet is set. Object keys containing "{" or "}"
+	// can't be written to a cluster.
+	Addresses []string
+	Sentinel  RedisSentinelConfig
+	TLS       RedisTLSConfig
+	// DisableCache must be set for servers without client side caching.
+	DisableCache bool
+	// Namespace prefixes every key, internal ones included.
+	Namespace string
+	// Databases routes URL hosts, compared case-insensitively, to
+	// databases, other hosts using the database of the DSN.
+	Databases map[string]int
+	// ChunkSize is the size above which objects are split in chunks.
+	ChunkSize int
+	// DefaultTTL applies to objects written without TTL nor matching prefix
+	// in TTLs.
+	DefaultTTL time.Duration
+	// TTLs maps key prefixes to the TTL of the objects written under them,
+	// the longest matching prefix winning.
+	TTLs map[string]time.Duration
+}
+
+type RedisSentinelConfig struct {
+	MasterSet string
+	Username  string
+	Password  string
+}
+
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
 }
 
 type RedisBackend struct {
 	conf       RedisBackendConfig
 	logger     *slog.Logger
+	ks         keyspace
 	clientLock sync.Mutex
-	client     rueidis.Client
+	clients    map[int]rueidis.Client
 	clientOpts rueidis.ClientOption
+	cluster    bool
 }
 
 func NewRedisBackend(ctx context.Context, conf RedisBackendConfig) (*RedisBackend, error) {
-	opts, err := rueidis.ParseURL(conf.Dsn)
+	opts, err := clientOptions(conf)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if conf.ChunkSize <= 0 {
+		conf.ChunkSize = defaultChunkSize
+	}
+
+	var databases = make(map[string]int, len(conf.Databases))
+
+	for host, db := range conf.Databases {
+		databases[strings.ToLower(host)] = db
+	}
+
+	conf.Databases = databases
+
 	return &RedisBackend{
 		conf:       conf,
 		logger:     slogctx.FromCtx(ctx),
+		ks:         keyspace{namespace: conf.Namespace},
+		clients:    make(map[int]rueidis.Client),
 		clientOpts: opts,
 	}, nil
 }
 
-func (be *RedisBackend) getClient(ctx context.Context) (rueidis.Client, error) {
+func clientOptions(conf RedisBackendConfig) (rueidis.ClientOption, error) {
+	var opts rueidis.ClientOption
+
+	if len(conf.Dsn) > 0 {
+		var err error
+
+		if opts, err = rueidis.ParseURL(conf.Dsn); err != nil {
+			return opts, err
+		}
+	}
+
+	if len(conf.Addresses) > 0 {
+		opts.InitAddress = conf.Addresses
+	}
+
+	if len(opts.InitAddress) == 0 {
+		return opts, fmt.Errorf("redis dsn or addresses must be specified")
+	}
+
+	if len(conf.Sentinel.MasterSet) > 0 {
+		opts.Sentinel.MasterSet = conf.Sentinel.MasterSet
+		opts.Sentinel.Username = conf.Sentinel.Username
+		opts.Sentinel.Password = conf.Sentinel.Password
+	}
+
+	tlsConfig, err := newTLSConfig(conf.TLS, opts.TLSConfig)
+
+	if err != nil {
+		return opts, err
+	}
+
+	opts.TLSConfig = tlsConfig
+	opts.Sentinel.TLSConfig = tlsConfig
+
+	if conf.DisableCache {
+		opts.DisableCache = true
+	}
+
+	return opts, nil
+}
+
+// newTLSConfig extends the TLS configuration of a rediss:// DSN, if any.
+func newTLSConfig(conf RedisTLSConfig, base *tls.Config) (*tls.Config, error) {
+	if !conf.Enabled && base == nil {
+		return nil, nil
+	}
+
+	var res = &tls.Config{}
+
+	if base != nil {
+		res = base.Clone()
+	}
+
+	if len(conf.CAFile) > 0 {
+		ca, err := os.ReadFile(conf.CAFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var pool = x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", conf.CAFile)
+		}
+
+		res.RootCAs = pool
+	}
+
+	if len(conf.CertFile) > 0 || len(conf.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(conf.ServerName) > 0 {
+		res.ServerName = conf.ServerName
+	}
+
+	if conf.InsecureSkipVerify {
+		res.InsecureSkipVerify = true
+	}
+
+	return res, nil
+}
+
+// getClient returns the client of the database the URL host is routed to,
+// creating it on first use.
+func (be *RedisBackend) getClient(ctx context.Context, u *url.URL) (rueidis.Client, error) {
+	var db = be.clientOpts.SelectDB
+
+	if d, found := be.conf.Databases[strings.ToLower(u.Hostname())]; found {
+		db = d
+	}
+
 	be.clientLock.Lock()
 	defer be.clientLock.Unlock()
 
-	if be.client != nil {
-		return be.client, nil
+	if client, found := be.clients[db]; found {
+		return client, nil
 	}
 
-	client, err := rueidis.NewClient(be.clientOpts)
+	var opts = be.clientOpts
+	opts.SelectDB = db
+
+	client, err := rueidis.NewClient(opts)
 
 	if err != nil {
 		return nil, err
 	}
 
-	be.client = client
-	return client, err
+	if len(be.clients) == 0 {
+		be.cluster = isCluster(ctx, client)
+	}
+
+	be.clients[db] = client
+	return client, nil
+}
+
+// isCluster reports whether the client routes commands by slot, which
+// rueidis does whenever the server answers CLUSTER SLOTS.
+func isCluster(ctx context.Context, client rueidis.Client) bool {
+	return client.Do(ctx, client.B().ClusterSlots().Build()).Error() == nil
 }
 
+// ListPrefix scans the keys starting with the prefix, then fetches their
+// metadata in a single pipeline.
 func (be *RedisBackend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...types.ListOption) ([]*types.Object, error) {
-	return nil, stderr.ErrUnsupported
+	var opts = types.NewListOptions(optFunc...)
+
+	client, err := be.getClient(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix = be.ks.objectKey(u)
+
+	// keys of the host "cache2" must not be listed under "redis://cache"
+	if len(u.Path) == 0 {
+		prefix += "/"
+	}
+
+	keys, err := be.scanKeys(ctx, client, prefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res  []*types.Object
+		cmds rueidis.Commands
+	)
+
+	for _, key := range keys {
+		var obj = types.Object{
+			URL: &url.URL{
+				Scheme: u.Scheme,
+				User:   u.User,
+				Host:   u.Host,
+				Path:   strings.TrimPrefix(key, be.ks.namespace+u.Hostname()),
+			},
+		}
+
+		if len(opts.StartAfter) > 0 && strings.Compare(obj.URL.String(), opts.StartAfter) <= 0 {
+			continue
+		}
+
+		res = append(res, &obj)
+		cmds = append(cmds, manifestCommands(client, be.ks, key)...)
+	}
+
+	if len(cmds) > 0 {
+		var (
+			resps = client.DoMulti(ctx, cmds...)
+			found = res[:0]
+		)
+
+		for i, obj := range res {
+			m, err := parseManifest(resps[i*3 : i*3+3])
+
+			// the key was deleted since it was scanned
+			if stderr.Is(err, errors.ErrObjectNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			obj.Metadata = m.metadata()
+			found = append(found, obj)
+		}
+
+		res = found
+	}
+
+	return types.GroupByDelimiter(u.Path, res, opts.Delimiter), nil
+}
+
+// scanKeys iterates SCAN MATCH prefix* on every node until the cursor wraps
+// around and returns the sorted keys, internal keys excluded. Keys seen on
+// both a primary and its replicas are only returned once.
+func (be *RedisBackend) scanKeys(ctx context.Context, client rueidis.Client, prefix string) ([]string, error) {
+	var res []string
+
+	for _, node := range client.Nodes() {
+		var cursor uint64
+
+		for {
+			entry, err := node.Do(ctx, node.B().Scan().Cursor(cursor).Match(escapePattern(prefix)+"*").Count(1000).Build()).AsScanEntry()
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, key := range entry.Elements {
+				if !be.ks.isInternalKey(key) {
+					res = append(res, key)
+				}
+			}
+
+			if cursor = entry.Cursor; cursor == 0 {
+				break
+			}
+		}
+	}
+
+	slices.Sort(res)
+	return slices.Compact(res), nil
+}
+
+// escapePattern escapes the glob meta characters of SCAN MATCH.
+func escapePattern(s string) string {
+	var res strings.Builder
+
+	for _, c := range s {
+		if strings.ContainsRune(`*?[]\`, c) {
+			res.WriteByte('\\')
+		}
+
+		res.WriteRune(c)
+	}
+
+	return res.String()
 }
 
 func (be *RedisBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
-	return nil, stderr.ErrUnsupported
+	client, err := be.getClient(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := readManifest(ctx, client, be.ks, be.ks.objectKey(u))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m.metadata(), nil
 }
 
 func (be *RedisBackend) Reader(ctx context.Context, u *url.URL) (types.Reader, error) {
-	client, err := be.getClient(ctx)
+	client, err := be.getClient(ctx, u)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var key = u.Hostname() + u.Path
+	var key = be.ks.objectKey(u)
 
-	r, err := client.Do(ctx, be.client.B().Get().Key(key).Build()).AsReader()
+	m, err := readManifest(ctx, client, be.ks, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Chunks > 1 {
+		return &chunkReader{ctx: ctx, client: client, ks: be.ks, key: key, m: m}, nil
+	}
+
+	r, err := client.Do(ctx, client.B().Get().Key(key).Build()).AsReader()
 
 	if stderr.Is(err, rueidis.Nil) {
 		return nil, errors.ErrObjectNotFound
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	return io.NopCloser(r), nil
 }
 
 func (be *RedisBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt, error) {
-	return nil, stderr.ErrUnsupported
+	client, err := be.getClient(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var key = be.ks.objectKey(u)
+
+	m, err := readManifest(ctx, client, be.ks, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &readerAt{ctx: ctx, client: client, ks: be.ks, key: key, m: m}, nil
 }
 
-func (be *RedisBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
-	var key = u.Hostname() + u.Path
+func (be *RedisBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
+	var (
+		key  = be.ks.objectKey(u)
+		opts = types.NewWriteOptions(optFuncs...)
+	)
 
-	client, err := be.getClient(ctx)
+	client, err := be.getClient(ctx, u)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return NewRedisWriter(client, key), nil
+	// the internal keys wouldn't share the slot of the object key, see
+	// keyspace
+	if be.cluster && strings.ContainsAny(key, "{}") {
+		return nil, fmt.Errorf("keys containing \"{\" or \"}\" are not supported on a redis cluster: %s", key)
+	}
+
+	return NewRedisWriter(ctx, client, be.ks, key, be.conf.ChunkSize, be.ttl(key, opts), opts.IfAbsent), nil
+}
+
+// ttl returns the TTL requested by the writer, or else the one of the
+// longest configured prefix of the key.
+func (be *RedisBackend) ttl(key string, opts *types.WriteOptions) time.Duration {
+	if opts.TTL > 0 {
+		return opts.TTL
+	}
+
+	var (
+		res     time.Duration
+		longest = -1
+	)
+
+	for prefix, ttl := range be.conf.TTLs {
+		if strings.HasPrefix(key, be.ks.namespace+prefix) && len(prefix) > longest {
+			res, longest = ttl, len(prefix)
+		}
+	}
+
+	if longest < 0 {
+		return be.conf.DefaultTTL
+	}
+
+	return res
 }
 
+// Delete removes the object, its chunks and its manifest.
 func (be *RedisBackend) Delete(ctx context.Context, u *url.URL) error {
-	client, err := be.getClient(ctx)
+	client, err := be.getClient(ctx, u)
 
 	if err != nil {
 		return err
 	}
 
-	var key = u.Hostname() + u.Path
-	return client.Do(ctx, be.client.B().Del().Key(key).Build()).Error()
+	var key = be.ks.objectKey(u)
+
+	m, err := readManifest(ctx, client, be.ks, key)
+
+	if stderr.Is(err, errors.ErrObjectNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return client.Do(ctx, client.B().Del().Key(append(m.keys(be.ks, key), be.ks.manifestKey(key))...).Build()).Error()
 }
 
 func (be *RedisBackend) Close() error {
-	if be.client != nil {
-		be.client.Close()
+	be.clientLock.Lock()
+	defer be.clientLock.Unlock()
+
+	for db, client := range be.clients {
+		client.Close()
+		delete(be.clients, db)
 	}
 
 	return nil
@@ -0,0 +1,175 @@
+package redis
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/redis/rueidis"
+)
+
+// An object is stored at its key when it fits in a chunk. Larger objects
+// keep their first chunk at the key and the following ones in chunk keys.
+// The manifest hash next to the key records the modification date, ETag,
+// total size and chunk layout. All internal keys wrap the object key in a
+// hash tag so that they share its cluster slot and can be updated in a
+// single transaction; none of them can collide with an object key since
+// hostnames can't contain ":". This only holds for keys without "{" nor "}",
+// which would change the part of the keys Redis hashes, so objects with such
+// keys can't be written to a cluster.
+const internalKeyPrefix = "objstr:"
+
+// keyspace names the keys of objects and of their internal keys, all of
+// them being prefixed with the configured namespace.
+type keyspace struct {
+	namespace string
+}
+
+// objectKey keeps the "<host><path>" naming of objects.
+func (ks keyspace) objectKey(u *url.URL) string {
+	return ks.namespace + u.Hostname() + u.Path
+}
+
+func (ks keyspace) manifestKey(key string) string {
+	return ks.namespace + internalKeyPrefix + "md:{" + key + "}"
+}
+
+// chunkKey returns the key of the i-th chunk, i > 0.
+func (ks keyspace) chunkKey(key string, i int) string {
+	return ks.namespace + internalKeyPrefix + "chunk:{" + key + "}:" + strconv.Itoa(i)
+}
+
+// uploadKey returns the key a chunk is staged at until the upload commits.
+func (ks keyspace) uploadKey(key string, uploadId string, i int) string {
+	return ks.namespace + internalKeyPrefix + "upload:{" + key + "}:" + uploadId + ":" + strconv.Itoa(i)
+}
+
+func (ks keyspace) isInternalKey(key string) bool {
+	return strings.HasPrefix(key, ks.namespace+internalKeyPrefix)
+}
+
+type manifest struct {
+	Size             uint64
+	ModificationDate time.Time
+	ETag             string
+	ChunkSize        int64
+	Chunks           int
+}
+
+func (m *manifest) metadata() *types.ObjectMetadata {
+	return &types.ObjectMetadata{
+		Size:             m.Size,
+		ModificationDate: m.ModificationDate,
+		ETag:             m.ETag,
+	}
+}
+
+func (m *manifest) fields() map[string]string {
+	return map[string]string{
+		"size":       strconv.FormatUint(m.Size, 10),
+		"mtime":      strconv.FormatInt(m.ModificationDate.UnixNano(), 10),
+		"etag":       m.ETag,
+		"chunk_size": strconv.FormatInt(m.ChunkSize, 10),
+		"chunks":     strconv.Itoa(m.Chunks),
+	}
+}
+
+// chunk returns the key holding the i-th chunk.
+func (m *manifest) chunk(ks keyspace, key string, i int) string {
+	if i == 0 {
+		return key
+	}
+
+	return ks.chunkKey(key, i)
+}
+
+var manifestFields = []string{"size", "mtime", "etag", "chunk_size", "chunks"}
+
+func manifestCommands(client rueidis.CoreClient, ks keyspace, key string) rueidis.Commands {
+	return rueidis.Commands{
+		client.B().Exists().Key(key).Build(),
+		client.B().Strlen().Key(key).Build(),
+		client.B().Hmget().Key(ks.manifestKey(key)).Field(manifestFields...).Build(),
+	}
+}
+
+// parseManifest decodes the replies of manifestCommands. Values written
+// without manifest are single chunk objects without modification date nor
+// ETag.
+func parseManifest(resps []rueidis.RedisResult) (*manifest, error) {
+	exists, err := resps[0].AsInt64()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exists == 0 {
+		return nil, errors.ErrObjectNotFound
+	}
+
+	strlen, err := resps[1].AsInt64()
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := resps[2].ToArray()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m      = manifest{Size: uint64(strlen), ChunkSize: strlen, Chunks: 1}
+		fields = make(map[string]string)
+	)
+
+	for i, name := range manifestFields {
+		if v, err := values[i].ToString(); err == nil {
+			fields[name] = v
+		}
+	}
+
+	if ns, err := strconv.ParseInt(fields["mtime"], 10, 64); err == nil {
+		m.ModificationDate = time.Unix(0, ns).UTC()
+	}
+
+	m.ETag = fields["etag"]
+
+	if chunks, err := strconv.Atoi(fields["chunks"]); err == nil && chunks > 1 {
+		size, err := strconv.ParseUint(fields["size"], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		chunkSize, err := strconv.ParseInt(fields["chunk_size"], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.Size, m.ChunkSize, m.Chunks = size, chunkSize, chunks
+	}
+
+	return &m, nil
+}
+
+func readManifest(ctx context.Context, client rueidis.CoreClient, ks keyspace, key string) (*manifest, error) {
+	return parseManifest(client.DoMulti(ctx, manifestCommands(client, ks, key)...))
+}
+
+// keys returns the keys holding the chunks of the object.
+func (m *manifest) keys(ks keyspace, key string) []string {
+	var res = make([]string, 0, m.Chunks)
+
+	for i := 0; i < max(m.Chunks, 1); i++ {
+		res = append(res, m.chunk(ks, key, i))
+	}
+
+	return res
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/redis/rueidis"
+)
+
+// chunkReader fetches the chunks of an object one at a time.
+type chunkReader struct {
+	ctx     context.Context
+	client  rueidis.Client
+	ks      keyspace
+	key     string
+	m       *manifest
+	next    int
+	current []byte
+	read    uint64
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if r.next >= r.m.Chunks {
+			if r.read != r.m.Size {
+				return 0, fmt.Errorf("object %s changed while being read", r.key)
+			}
+
+			return 0, io.EOF
+		}
+
+		chunk, err := r.client.Do(r.ctx, r.client.B().Get().Key(r.m.chunk(r.ks, r.key, r.next)).Build()).AsBytes()
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to read chunk %d of %s: %w", r.next, r.key, err)
+		}
+
+		r.current = chunk
+		r.next++
+	}
+
+	var n = copy(p, r.current)
+
+	r.current = r.current[n:]
+	r.read += uint64(n)
+	return n, nil
+}
+
+func (r *chunkReader) Close() error {
+	r.current = nil
+	return nil
+}
+
+// readerAt serves ranges with GETRANGE on the chunks they span.
+type readerAt struct {
+	ctx    context.Context
+	client rueidis.Client
+	ks     keyspace
+	key    string
+	m      *manifest
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	var total int
+
+	for len(p) > 0 {
+		if uint64(off) >= r.m.Size {
+			return total, io.EOF
+		}
+
+		var (
+			i     = off / r.m.ChunkSize
+			start = off % r.m.ChunkSize
+			n     = min(int64(len(p)), r.m.ChunkSize-start, int64(r.m.Size)-off)
+		)
+
+		data, err := r.client.Do(
+			r.ctx,
+			r.client.B().Getrange().Key(r.m.chunk(r.ks, r.key, int(i))).Start(start).End(start+n-1).Build(),
+		).AsBytes()
+
+		if err != nil {
+			return total, err
+		}
+
+		if int64(len(data)) != n {
+			return total, fmt.Errorf("object %s changed while being read", r.key)
+		}
+
+		copy(p, data)
+		p, off, total = p[n:], off+n, total+int(n)
+	}
+
+	return total, nil
+}
+
+func (r *readerAt) Close() error {
+	return nil
+}
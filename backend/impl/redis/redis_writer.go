@@ -1,26 +1,226 @@
 package redis
 
 import (
-	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	stderr "errors"
+	"fmt"
+	"hash"
+	"time"
 
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/google/uuid"
 	"github.com/redis/rueidis"
 )
 
+// uploadTTL bounds the lifetime of chunks staged by writers that never
+// commit nor abort.
+const uploadTTL = 24 * time.Hour
+
+// RedisWriter holds at most one chunk in memory. Full chunks are staged
+// under upload keys, then Close moves them in place together with the
+// manifest in a single MULTI, so readers never see a partial object.
 type RedisWriter struct {
-	client rueidis.Client
-	key    string
-	buf    bytes.Buffer
+	ctx       context.Context
+	client    rueidis.Client
+	ks        keyspace
+	key       string
+	chunkSize int
+	ttl       time.Duration
+	ifAbsent  bool
+	uploadId  string
+	buf       []byte
+	chunks    int
+	size      uint64
+	hash      hash.Hash
 }
 
-func NewRedisWriter(client rueidis.Client, key string) *RedisWriter {
-	return &RedisWriter{client: client, key: key}
+func NewRedisWriter(
+	ctx context.Context,
+	client rueidis.Client,
+	ks keyspace,
+	key string,
+	chunkSize int,
+	ttl time.Duration,
+	ifAbsent bool,
+) *RedisWriter {
+	return &RedisWriter{
+		ctx:       ctx,
+		client:    client,
+		ks:        ks,
+		key:       key,
+		chunkSize: chunkSize,
+		ttl:       ttl,
+		ifAbsent:  ifAbsent,
+		uploadId:  uuid.Must(uuid.NewV7()).String(),
+		buf:       make([]byte, 0, chunkSize),
+		hash:      md5.New(),
+	}
 }
 
 func (w *RedisWriter) Write(data []byte) (int, error) {
-	return w.buf.Write(data)
+	var total = len(data)
+
+	for len(data) > 0 {
+		if len(w.buf) == w.chunkSize {
+			if err := w.stage(); err != nil {
+				return total - len(data), err
+			}
+		}
+
+		var n = min(w.chunkSize-len(w.buf), len(data))
+
+		w.buf = append(w.buf, data[:n]...)
+		w.hash.Write(data[:n])
+		w.size += uint64(n)
+		data = data[n:]
+	}
+
+	return total, nil
 }
 
+func (w *RedisWriter) stage() error {
+	var err = w.client.Do(
+		w.ctx,
+		w.client.B().Set().Key(w.ks.uploadKey(w.key, w.uploadId, w.chunks)).Value(rueidis.BinaryString(w.buf)).Ex(uploadTTL).Build(),
+	).Error()
+
+	if err != nil {
+		return err
+	}
+
+	w.chunks++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close commits the object; an object that fits in a chunk is stored at its
+// key as a plain value.
 func (w *RedisWriter) Close() error {
-	return w.client.Do(context.Background(), w.client.B().Set().Key(w.key).Value(w.buf.String()).Build()).Error()
+	if w.chunks > 0 {
+		if err := w.stage(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	if err := w.commit(); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return nil
+}
+
+func (w *RedisWriter) commit() error {
+	return w.client.Dedicated(func(c rueidis.DedicatedClient) error {
+		if w.ifAbsent {
+			if err := c.Do(w.ctx, c.B().Watch().Key(w.key).Build()).Error(); err != nil {
+				return err
+			}
+		}
+
+		previous, err := readManifest(w.ctx, c, w.ks, w.key)
+
+		switch {
+		case err == nil && w.ifAbsent:
+			c.Do(w.ctx, c.B().Unwatch().Build())
+			return errors.ErrObjectAlreadyExists
+		case err == nil:
+		case stderr.Is(err, errors.ErrObjectNotFound):
+			previous = &manifest{}
+		default:
+			return err
+		}
+
+		var (
+			sum = w.hash.Sum(nil)
+			m   = manifest{
+				Size:             w.size,
+				ModificationDate: time.Now(),
+				ETag:             hex.EncodeToString(sum),
+				ChunkSize:        int64(w.chunkSize),
+				Chunks:           max(w.chunks, 1),
+			}
+			cmds = rueidis.Commands{c.B().Multi().Build()}
+		)
+
+		if w.chunks == 0 {
+			cmds = append(cmds, c.B().Set().Key(w.key).Value(rueidis.BinaryString(w.buf)).Build())
+		}
+
+		for i := 0; i < w.chunks; i++ {
+			cmds = append(cmds, c.B().Rename().Key(w.ks.uploadKey(w.key, w.uploadId, i)).Newkey(m.chunk(w.ks, w.key, i)).Build())
+		}
+
+		for i := max(m.Chunks, 1); i < previous.Chunks; i++ {
+			cmds = append(cmds, c.B().Del().Key(w.ks.chunkKey(w.key, i)).Build())
+		}
+
+		cmds = append(cmds, c.B().Del().Key(w.ks.manifestKey(w.key)).Build())
+
+		var hset = c.B().Hset().Key(w.ks.manifestKey(w.key)).FieldValue()
+
+		for k, v := range m.fields() {
+			hset = hset.FieldValue(k, v)
+		}
+
+		cmds = append(cmds, hset.Build())
+
+		for _, key := range append(m.keys(w.ks, w.key), w.ks.manifestKey(w.key)) {
+			if w.ttl > 0 {
+				cmds = append(cmds, c.B().Pexpire().Key(key).Milliseconds(w.ttl.Milliseconds()).Build())
+			} else {
+				cmds = append(cmds, c.B().Persist().Key(key).Build())
+			}
+		}
+
+		cmds = append(cmds, c.B().Exec().Build())
+
+		var resps = c.DoMulti(w.ctx, cmds...)
+
+		for _, resp := range resps[:len(resps)-1] {
+			if err := resp.Error(); err != nil {
+				return err
+			}
+		}
+
+		res, err := resps[len(resps)-1].ToArray()
+
+		if rueidis.IsRedisNil(err) {
+			return errors.ErrObjectAlreadyExists
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, r := range res {
+			if err := r.Error(); err != nil {
+				return fmt.Errorf("failed to commit %s: %w", w.key, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Abort removes the staged chunks.
+func (w *RedisWriter) Abort() error {
+	var cmds rueidis.Commands
+
+	for i := 0; i < w.chunks; i++ {
+		cmds = append(cmds, w.client.B().Del().Key(w.ks.uploadKey(w.key, w.uploadId, i)).Build())
+	}
+
+	w.buf, w.chunks = w.buf[:0], 0
+
+	for _, resp := range w.client.DoMulti(context.Background(), cmds...) {
+		if err := resp.Error(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -161,6 +162,10 @@ func (be *S3Backend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...type
 		input.SetBucket(u.Host)
 		input.SetPrefix(strings.TrimPrefix(u.Path, "/"))
 
+		if len(opts.Delimiter) > 0 {
+			input.SetDelimiter(opts.Delimiter)
+		}
+
 		if len(opts.StartAfter) > 0 {
 			startAfterUrl, err := url.Parse(opts.StartAfter)
 
@@ -208,6 +213,23 @@ func (be *S3Backend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...type
 
 			res = append(res, &obj)
 		}
+
+		for _, commonPrefix := range output.CommonPrefixes {
+			res = append(res, &types.Object{
+				URL: &url.URL{
+					Host: *output.Name,
+					Path: "/" + *commonPrefix.Prefix,
+				},
+				Metadata: &types.ObjectMetadata{},
+				IsPrefix: true,
+			})
+		}
+	}
+
+	if len(opts.Delimiter) > 0 {
+		sort.Slice(res, func(i, j int) bool {
+			return res[i].URL.Path < res[j].URL.Path
+		})
 	}
 
 	return res, nil
@@ -300,7 +322,7 @@ func (be *S3Backend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt,
 	return news3ReaderAt(ctx, be.s3Svc, u)
 }
 
-func (be *S3Backend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *S3Backend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	if err := be.validateURL(u); err != nil {
 		return nil, err
 	}
@@ -315,6 +337,7 @@ func (be *S3Backend) Writer(ctx context.Context, u *url.URL) (types.Writer, erro
 		be.awsSession,
 		u,
 		s3wConf,
+		types.NewWriteOptions(optFuncs...),
 	), nil
 }
 
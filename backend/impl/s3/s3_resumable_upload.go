@@ -0,0 +1,342 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	minUploadPartSize = 5 * 1024 * 1024
+	maxUploadParts    = 10000
+)
+
+type resumableUploadPart struct {
+	PartNumber int64
+	ETag       string
+	Size       int64
+}
+
+// resumableUploadState is saved after each part; the source's URL, ETag and
+// modification date identify the content being uploaded, so that parts of a
+// source that changed since are never completed with new ones.
+type resumableUploadState struct {
+	Bucket                 string
+	Key                    string
+	Size                   int64
+	SourceURL              string
+	SourceETag             string
+	SourceModificationDate time.Time
+	PartSize               int64
+	UploadId               string
+	Parts                  []resumableUploadPart
+}
+
+type resumableUpload struct {
+	svc       *s3.S3
+	logger    *slog.Logger
+	statePath string
+	lock      sync.Mutex
+	state     resumableUploadState
+}
+
+func (be *S3Backend) ResumableUpload(ctx context.Context, u *url.URL, r types.ReaderAt, src *types.Object, statePath string) error {
+	if err := be.validateURL(u); err != nil {
+		return err
+	}
+
+	var size = src.Metadata.Size
+
+	if size == 0 {
+		input := &s3.PutObjectInput{}
+		input = input.SetBucket(u.Host)
+		input = input.SetKey(u.Path)
+		input = input.SetBody(bytes.NewReader(nil))
+
+		_, err := be.s3Svc.PutObjectWithContext(ctx, input)
+		return processError(err)
+	}
+
+	var upload = resumableUpload{
+		svc:       be.s3Svc,
+		logger:    be.logger,
+		statePath: statePath,
+	}
+
+	if err := upload.init(ctx, u, src, int64(be.conf.UploadPartSize)); err != nil {
+		return err
+	}
+
+	if err := upload.uploadMissingParts(ctx, r, be.conf.UploadConcurrency); err != nil {
+		return err
+	}
+
+	return upload.complete(ctx)
+}
+
+func (up *resumableUpload) init(ctx context.Context, u *url.URL, src *types.Object, partSize int64) error {
+	var size = int64(src.Metadata.Size)
+
+	state, err := loadResumableUploadState(up.statePath)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case state == nil:
+
+	case state.Bucket == u.Host && state.Key == u.Path && state.matchesSource(src):
+		parts, err := up.listParts(ctx, state)
+
+		if err == nil {
+			state.Parts = parts
+			up.state = *state
+			return up.save()
+		}
+
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != "NoSuchUpload" {
+			return err
+		}
+
+	default:
+		// the source changed since the state was saved: its parts are stale
+		up.abort(ctx, state)
+	}
+
+	partSize = max(partSize, minUploadPartSize, int64(math.Ceil(float64(size)/maxUploadParts)))
+
+	input := &s3.CreateMultipartUploadInput{}
+	input = input.SetBucket(u.Host)
+	input = input.SetKey(u.Path)
+
+	output, err := up.svc.CreateMultipartUploadWithContext(ctx, input)
+
+	if err != nil {
+		return processError(err)
+	}
+
+	up.state = resumableUploadState{
+		Bucket:                 u.Host,
+		Key:                    u.Path,
+		Size:                   size,
+		SourceURL:              src.URL.String(),
+		SourceETag:             src.Metadata.ETag,
+		SourceModificationDate: src.Metadata.ModificationDate,
+		PartSize:               partSize,
+		UploadId:               *output.UploadId,
+	}
+
+	return up.save()
+}
+
+func (state *resumableUploadState) matchesSource(src *types.Object) bool {
+	return state.SourceURL == src.URL.String() &&
+		state.Size == int64(src.Metadata.Size) &&
+		state.SourceETag == src.Metadata.ETag &&
+		state.SourceModificationDate.Equal(src.Metadata.ModificationDate)
+}
+
+// abort discards a stale multipart upload; failing to do so only leaves
+// parts behind until the bucket's lifecycle rules clean them up.
+func (up *resumableUpload) abort(ctx context.Context, state *resumableUploadState) {
+	input := &s3.AbortMultipartUploadInput{}
+	input = input.SetBucket(state.Bucket)
+	input = input.SetKey(state.Key)
+	input = input.SetUploadId(state.UploadId)
+
+	_, err := up.svc.AbortMultipartUploadWithContext(ctx, input)
+
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchUpload" {
+		return
+	}
+
+	if err != nil {
+		up.logger.Warn("failed to abort stale multipart upload", "upload_id", state.UploadId, "error", err)
+	}
+}
+
+func (up *resumableUpload) listParts(ctx context.Context, state *resumableUploadState) ([]resumableUploadPart, error) {
+	var res []resumableUploadPart
+
+	input := &s3.ListPartsInput{}
+	input = input.SetBucket(state.Bucket)
+	input = input.SetKey(state.Key)
+	input = input.SetUploadId(state.UploadId)
+
+	err := up.svc.ListPartsPagesWithContext(ctx, input, func(output *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range output.Parts {
+			var (
+				partNumber   = aws.Int64Value(part.PartNumber)
+				expectedSize = min(state.PartSize, state.Size-(partNumber-1)*state.PartSize)
+			)
+
+			// parts that were only partially uploaded are uploaded again
+			if aws.Int64Value(part.Size) != expectedSize {
+				continue
+			}
+
+			res = append(res, resumableUploadPart{
+				PartNumber: partNumber,
+				ETag:       aws.StringValue(part.ETag),
+				Size:       aws.Int64Value(part.Size),
+			})
+		}
+
+		return true
+	})
+
+	return res, err
+}
+
+func (up *resumableUpload) uploadMissingParts(ctx context.Context, r types.ReaderAt, concurrency int) error {
+	var (
+		numParts = int64(math.Ceil(float64(up.state.Size) / float64(up.state.PartSize)))
+		done     = make(map[int64]bool)
+	)
+
+	for _, part := range up.state.Parts {
+		done[part.PartNumber] = true
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(concurrency, 1))
+
+	for partNumber := int64(1); partNumber <= numParts; partNumber++ {
+		if done[partNumber] {
+			continue
+		}
+
+		group.Go(func() error {
+			return up.uploadPart(groupCtx, r, partNumber)
+		})
+	}
+
+	return group.Wait()
+}
+
+func (up *resumableUpload) uploadPart(ctx context.Context, r types.ReaderAt, partNumber int64) error {
+	var (
+		off  = (partNumber - 1) * up.state.PartSize
+		size = min(up.state.PartSize, up.state.Size-off)
+		buf  = make([]byte, size)
+	)
+
+	n, err := r.ReadAt(buf, off)
+
+	if err != nil && !(errors.Is(err, io.EOF) && int64(n) == size) {
+		return err
+	}
+
+	input := &s3.UploadPartInput{}
+	input = input.SetBucket(up.state.Bucket)
+	input = input.SetKey(up.state.Key)
+	input = input.SetUploadId(up.state.UploadId)
+	input = input.SetPartNumber(partNumber)
+	input = input.SetBody(bytes.NewReader(buf))
+
+	output, err := up.svc.UploadPartWithContext(ctx, input)
+
+	if err != nil {
+		return processError(err)
+	}
+
+	up.lock.Lock()
+	defer up.lock.Unlock()
+
+	up.state.Parts = append(up.state.Parts, resumableUploadPart{
+		PartNumber: partNumber,
+		ETag:       aws.StringValue(output.ETag),
+		Size:       size,
+	})
+
+	return up.save()
+}
+
+func (up *resumableUpload) complete(ctx context.Context) error {
+	sort.Slice(up.state.Parts, func(i, j int) bool {
+		return up.state.Parts[i].PartNumber < up.state.Parts[j].PartNumber
+	})
+
+	var completedParts []*s3.CompletedPart
+
+	for _, part := range up.state.Parts {
+		completedParts = append(completedParts, &s3.CompletedPart{
+			PartNumber: aws.Int64(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	input := &s3.CompleteMultipartUploadInput{}
+	input = input.SetBucket(up.state.Bucket)
+	input = input.SetKey(up.state.Key)
+	input = input.SetUploadId(up.state.UploadId)
+	input = input.SetMultipartUpload(&s3.CompletedMultipartUpload{Parts: completedParts})
+
+	if _, err := up.svc.CompleteMultipartUploadWithContext(ctx, input); err != nil {
+		return processError(err)
+	}
+
+	// the object is complete at this point, a leftover state is harmless
+	if err := os.Remove(up.statePath); err != nil {
+		up.logger.Warn("failed to remove resumable upload state", "path", up.statePath, "error", err)
+	}
+
+	return nil
+}
+
+func (up *resumableUpload) save() error {
+	content, err := json.Marshal(up.state)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(up.statePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	var tmpPath = up.statePath + ".tmp"
+
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, up.statePath)
+}
+
+func loadResumableUploadState(path string) (*resumableUploadState, error) {
+	content, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var state resumableUploadState
+
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
@@ -2,15 +2,24 @@ package s3
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/http"
 	"net/url"
 
+	objstrerrors "github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/hashicorp/go-multierror"
 	"golang.org/x/sync/errgroup"
 )
 
+var errAborted = errors.New("upload aborted")
+
 type s3WriterConfig struct {
 	PartSize    int
 	Concurrency int
@@ -20,12 +29,13 @@ type s3Writer struct {
 	conf  s3WriterConfig
 	sess  *session.Session
 	u     *url.URL
+	opts  *types.WriteOptions
 	r     *io.PipeReader
 	w     *io.PipeWriter
 	group *errgroup.Group
 }
 
-func newS3Writer(ctx context.Context, sess *session.Session, u *url.URL, conf s3WriterConfig) *s3Writer {
+func newS3Writer(ctx context.Context, sess *session.Session, u *url.URL, conf s3WriterConfig, opts *types.WriteOptions) *s3Writer {
 	r, w := io.Pipe()
 
 	group, ctx := errgroup.WithContext(ctx)
@@ -34,6 +44,7 @@ func newS3Writer(ctx context.Context, sess *session.Session, u *url.URL, conf s3
 		conf:  conf,
 		sess:  sess,
 		u:     u,
+		opts:  opts,
 		r:     r,
 		w:     w,
 		group: group,
@@ -52,15 +63,62 @@ func (s3w *s3Writer) runUploader() error {
 		u.Concurrency = s3w.conf.Concurrency
 	})
 
-	_, err := uploader.Upload(&s3manager.UploadInput{
+	var input = s3manager.UploadInput{
 		Bucket: &s3w.u.Host,
 		Key:    &s3w.u.Path,
 		Body:   s3w.r,
-	})
+	}
+
+	if len(s3w.opts.ContentType) > 0 {
+		input.ContentType = aws.String(s3w.opts.ContentType)
+	}
+
+	if len(s3w.opts.Metadata) > 0 {
+		input.Metadata = aws.StringMap(s3w.opts.Metadata)
+	}
+
+	if s3w.opts.IfAbsent {
+		uploader.RequestOptions = append(uploader.RequestOptions, ifNoneMatchOption)
+	}
+
+	_, err := uploader.Upload(&input)
+
+	if s3w.opts.IfAbsent && isPreconditionFailed(err) {
+		return objstrerrors.ErrObjectAlreadyExists
+	}
 
 	return err
 }
 
+// ifNoneMatchOption makes the requests that create the object conditional,
+// the SDK version in use has no field for it.
+func ifNoneMatchOption(r *request.Request) {
+	switch r.Operation.Name {
+	case "PutObject", "CompleteMultipartUpload":
+		r.HTTPRequest.Header.Set("If-None-Match", "*")
+	}
+}
+
+// isPreconditionFailed looks through the error chain of the uploader, whose
+// errors don't implement Unwrap.
+func isPreconditionFailed(err error) bool {
+	for err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusPreconditionFailed {
+			return true
+		}
+
+		awsErr, ok := err.(awserr.Error)
+
+		if !ok {
+			return false
+		}
+
+		err = awsErr.OrigErr()
+	}
+
+	return false
+}
+
 func (s3w *s3Writer) Write(data []byte) (int, error) {
 	return s3w.w.Write(data)
 }
@@ -78,3 +136,12 @@ func (s3w *s3Writer) Close() error {
 
 	return res.ErrorOrNil()
 }
+
+func (s3w *s3Writer) Abort() error {
+	s3w.w.CloseWithError(errAborted)
+
+	// the uploader fails with errAborted and aborts the multipart upload
+	// on its own, so its error is expected here
+	s3w.group.Wait()
+	return nil
+}
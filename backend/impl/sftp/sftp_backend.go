@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/agnosticeng/objstr/errors"
 	"github.com/agnosticeng/objstr/types"
 	slogctx "github.com/veqryn/slog-context"
 )
@@ -84,7 +86,7 @@ func (be *SFTPBackend) ListPrefix(ctx context.Context, u *url.URL, optFunc ...ty
 		})
 	}
 
-	return res, nil
+	return types.GroupByDelimiter(u.Path, res, opts.Delimiter), nil
 }
 
 func (be *SFTPBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
@@ -94,12 +96,20 @@ func (be *SFTPBackend) ReadMetadata(ctx context.Context, u *url.URL) (*types.Obj
 		return nil, fmt.Errorf("failed to get client for %s: %w", u.String(), err)
 	}
 
-	stat, err := client.SFTPClient().Stat(filepath.Join(u.Host, u.Path))
+	stat, err := client.SFTPClient().Stat(u.Path)
+
+	if os.IsNotExist(err) {
+		return nil, errors.ErrObjectNotFound
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	if !stat.Mode().IsRegular() {
+		return nil, errors.ErrObjectNotFound
+	}
+
 	return &types.ObjectMetadata{
 		Size:             uint64(stat.Size()),
 		ModificationDate: stat.ModTime(),
@@ -126,7 +136,7 @@ func (be *SFTPBackend) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt
 	return client.SFTPClient().Open(u.Path)
 }
 
-func (be *SFTPBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (be *SFTPBackend) Writer(ctx context.Context, u *url.URL, optFuncs ...types.WriteOption) (types.Writer, error) {
 	client, err := be.clientCache.Get(ctx, u)
 
 	if err != nil {
@@ -137,7 +147,7 @@ func (be *SFTPBackend) Writer(ctx context.Context, u *url.URL) (types.Writer, er
 		return nil, err
 	}
 
-	return client.SFTPClient().Create(u.Path)
+	return NewSFTPWriter(client.SFTPClient(), u.Path, types.NewWriteOptions(optFuncs...).IfAbsent)
 }
 
 func (be *SFTPBackend) Delete(ctx context.Context, u *url.URL) error {
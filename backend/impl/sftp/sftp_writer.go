@@ -0,0 +1,83 @@
+package sftp
+
+import (
+	"path"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/sftp"
+)
+
+type SFTPWriter struct {
+	client   *sftp.Client
+	path     string
+	tmpPath  string
+	ifAbsent bool
+	f        *sftp.File
+}
+
+func NewSFTPWriter(client *sftp.Client, p string, ifAbsent bool) (*SFTPWriter, error) {
+	var tmpPath = path.Join(path.Dir(p), "."+path.Base(p)+"."+uuid.Must(uuid.NewV7()).String()+".tmp")
+
+	f, err := client.Create(tmpPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SFTPWriter{
+		client:   client,
+		path:     p,
+		tmpPath:  tmpPath,
+		ifAbsent: ifAbsent,
+		f:        f,
+	}, nil
+}
+
+func (w *SFTPWriter) Write(data []byte) (int, error) {
+	return w.f.Write(data)
+}
+
+func (w *SFTPWriter) Close() error {
+	if err := w.f.Close(); err != nil {
+		w.client.Remove(w.tmpPath)
+		return err
+	}
+
+	if w.ifAbsent {
+		// the standard SFTP rename fails when the destination exists
+		if err := w.client.Rename(w.tmpPath, w.path); err != nil {
+			w.client.Remove(w.tmpPath)
+
+			if _, statErr := w.client.Stat(w.path); statErr == nil {
+				return errors.ErrObjectAlreadyExists
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	if err := w.client.PosixRename(w.tmpPath, w.path); err != nil {
+		w.client.Remove(w.tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+func (w *SFTPWriter) Abort() error {
+	var res *multierror.Error
+
+	if err := w.f.Close(); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	if err := w.client.Remove(w.tmpPath); err != nil {
+		res = multierror.Append(res, err)
+	}
+
+	return res.ErrorOrNil()
+}
@@ -0,0 +1,44 @@
+package objstr
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"net/url"
+	"slices"
+
+	"github.com/agnosticeng/objstr/types"
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"crc32c": func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+}
+
+func ChecksumAlgorithms() []string {
+	var res []string
+
+	for algorithm := range checksumAlgorithms {
+		res = append(res, algorithm)
+	}
+
+	slices.Sort(res)
+	return res
+}
+
+// Checksum reads the whole object and returns its hex encoded digest.
+func (os *ObjectStore) Checksum(ctx context.Context, u *url.URL, algorithm string) (string, error) {
+	newHash, found := checksumAlgorithms[algorithm]
+
+	if !found {
+		return "", fmt.Errorf("unknown checksum algorithm: %s", algorithm)
+	}
+
+	return os.hashObject(ctx, &types.Object{URL: u}, newHash())
+}
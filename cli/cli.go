@@ -3,6 +3,7 @@ package cli
 import (
 	"github.com/agnosticeng/cnf"
 	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/types"
 	"github.com/urfave/cli/v2"
 )
 
@@ -32,3 +33,35 @@ func ObjStrAfter(ctx *cli.Context) error {
 
 	return nil
 }
+
+func VerifyFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "verify",
+		Value: string(objstr.CompareSize),
+		Usage: "check each destination against its source before deleting the source: none, size, size-mtime, etag or hash",
+	}
+}
+
+// VerifyOptions returns the copy options matching the verify flag.
+func VerifyOptions(ctx *cli.Context) ([]types.CopyOption, error) {
+	var mode = ctx.String("verify")
+
+	if mode == "none" {
+		return nil, nil
+	}
+
+	compareMode, err := objstr.ParseCompareMode(mode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []types.CopyOption{types.WithVerify(string(compareMode))}, nil
+}
+
+func GlobFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "glob",
+		Usage: "expand the path as a glob pattern even for http(s) and git URLs, whose \"?\" otherwise starts the query; escape *?[{ with \\ to address a literal key",
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMaxDelete parses a delete limit given either as an object count
+// ("100") or as a share of the destination objects ("10%").
+func ParseMaxDelete(s string) (int, float64, error) {
+	if len(s) == 0 {
+		return 0, 0, nil
+	}
+
+	if rawPercent, found := strings.CutSuffix(s, "%"); found {
+		percent, err := strconv.ParseFloat(rawPercent, 64)
+
+		if err != nil || percent < 0 {
+			return 0, 0, fmt.Errorf("invalid delete limit: %s", s)
+		}
+
+		return 0, percent, nil
+	}
+
+	n, err := strconv.Atoi(s)
+
+	if err != nil || n < 0 {
+		return 0, 0, fmt.Errorf("invalid delete limit: %s", s)
+	}
+
+	return n, 0, nil
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+)
+
+const goTemplatePrefix = "go-template="
+
+// Record is a single line of command output. Its exported fields and their
+// json tags are used by the json, ndjson and go-template formats.
+type Record interface {
+	Text() string
+	CSVHeader() []string
+	CSVRow() []string
+}
+
+func OutputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "text",
+			EnvVars: []string{"OBJSTR_OUTPUT"},
+			Usage:   "output format: text, json, ndjson, csv or go-template=<template>",
+		},
+	}
+}
+
+// Printer writes records in the format selected with the output flag. It is
+// safe for concurrent use; json output is buffered until Flush.
+type Printer struct {
+	lock       sync.Mutex
+	format     string
+	w          io.Writer
+	tmpl       *template.Template
+	csv        *csv.Writer
+	csvHeader  bool
+	jsonBuffer []Record
+}
+
+func NewPrinter(ctx *cli.Context) (*Printer, error) {
+	return NewPrinterWithFormat(ctx.String("output"), os.Stdout)
+}
+
+func NewPrinterWithFormat(format string, w io.Writer) (*Printer, error) {
+	var p = Printer{format: format, w: w}
+
+	switch {
+	case len(format) == 0:
+		p.format = "text"
+
+	case format == "text", format == "json", format == "ndjson":
+
+	case format == "csv":
+		p.csv = csv.NewWriter(w)
+
+	case strings.HasPrefix(format, goTemplatePrefix):
+		tmpl, err := template.New("output").Parse(strings.TrimPrefix(format, goTemplatePrefix))
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.format = "go-template"
+		p.tmpl = tmpl
+
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", format)
+	}
+
+	return &p, nil
+}
+
+// IsText reports whether the human readable format is selected, commands
+// only print summaries in that case.
+func (p *Printer) IsText() bool {
+	return p.format == "text"
+}
+
+func (p *Printer) Print(record Record) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch p.format {
+	case "json":
+		p.jsonBuffer = append(p.jsonBuffer, record)
+		return nil
+
+	case "ndjson":
+		return json.NewEncoder(p.w).Encode(record)
+
+	case "csv":
+		if !p.csvHeader {
+			if err := p.csv.Write(record.CSVHeader()); err != nil {
+				return err
+			}
+
+			p.csvHeader = true
+		}
+
+		return p.csv.Write(record.CSVRow())
+
+	case "go-template":
+		if err := p.tmpl.Execute(p.w, record); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintln(p.w)
+		return err
+
+	default:
+		_, err := fmt.Fprintln(p.w, record.Text())
+		return err
+	}
+}
+
+func (p *Printer) Flush() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch p.format {
+	case "json":
+		var records = p.jsonBuffer
+
+		if records == nil {
+			records = []Record{}
+		}
+
+		var enc = json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case "csv":
+		p.csv.Flush()
+		return p.csv.Error()
+
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/dustin/go-humanize"
+)
+
+type ObjectRecord struct {
+	URL              string    `json:"url"`
+	Size             uint64    `json:"size"`
+	ModificationDate time.Time `json:"modification_date"`
+	ETag             string    `json:"etag,omitempty"`
+	IsPrefix         bool      `json:"is_prefix,omitempty"`
+}
+
+func NewObjectRecord(obj *types.Object) *ObjectRecord {
+	var res = ObjectRecord{
+		URL:      obj.URL.String(),
+		IsPrefix: obj.IsPrefix,
+	}
+
+	if obj.Metadata != nil {
+		res.Size = obj.Metadata.Size
+		res.ModificationDate = obj.Metadata.ModificationDate
+		res.ETag = obj.Metadata.ETag
+	}
+
+	return &res
+}
+
+func (r *ObjectRecord) Text() string {
+	return strings.TrimSuffix(fmt.Sprintln(r.URL, humanize.Bytes(r.Size), r.ModificationDate, r.ETag), "\n")
+}
+
+func (r *ObjectRecord) CSVHeader() []string {
+	return []string{"url", "size", "modification_date", "etag", "is_prefix"}
+}
+
+func (r *ObjectRecord) CSVRow() []string {
+	return []string{
+		r.URL,
+		strconv.FormatUint(r.Size, 10),
+		formatTime(r.ModificationDate),
+		r.ETag,
+		strconv.FormatBool(r.IsPrefix),
+	}
+}
+
+type DiffRecord struct {
+	Kind      string  `json:"kind"`
+	Path      string  `json:"path"`
+	LeftURL   string  `json:"left_url,omitempty"`
+	RightURL  string  `json:"right_url,omitempty"`
+	LeftSize  *uint64 `json:"left_size,omitempty"`
+	RightSize *uint64 `json:"right_size,omitempty"`
+	LeftETag  string  `json:"left_etag,omitempty"`
+	RightETag string  `json:"right_etag,omitempty"`
+}
+
+func NewDiffRecord(diff objstr.Difference) *DiffRecord {
+	var res = DiffRecord{
+		Kind: diff.Kind.String(),
+		Path: diff.Path,
+	}
+
+	if diff.Left != nil {
+		res.LeftURL = diff.Left.URL.String()
+		res.LeftSize = &diff.Left.Metadata.Size
+		res.LeftETag = diff.Left.Metadata.ETag
+	}
+
+	if diff.Right != nil {
+		res.RightURL = diff.Right.URL.String()
+		res.RightSize = &diff.Right.Metadata.Size
+		res.RightETag = diff.Right.Metadata.ETag
+	}
+
+	return &res
+}
+
+func (r *DiffRecord) Text() string {
+	if r.Kind == objstr.DiffSizeDiffers.String() {
+		return strings.Join([]string{
+			r.Kind, r.Path,
+			"LEFT", humanize.Bytes(*r.LeftSize),
+			"RIGHT", humanize.Bytes(*r.RightSize),
+		}, " ")
+	}
+
+	return r.Kind + " " + r.Path
+}
+
+func (r *DiffRecord) CSVHeader() []string {
+	return []string{"kind", "path", "left_url", "right_url", "left_size", "right_size", "left_etag", "right_etag"}
+}
+
+func (r *DiffRecord) CSVRow() []string {
+	return []string{
+		r.Kind,
+		r.Path,
+		r.LeftURL,
+		r.RightURL,
+		formatOptionalSize(r.LeftSize),
+		formatOptionalSize(r.RightSize),
+		r.LeftETag,
+		r.RightETag,
+	}
+}
+
+type SyncRecord struct {
+	Action string `json:"action"`
+	Src    string `json:"src,omitempty"`
+	Dst    string `json:"dst,omitempty"`
+	// Status is "ok", "failed" or "planned" for dry runs.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewSyncRecord(res objstr.SyncResult, dryRun bool) *SyncRecord {
+	var rec = SyncRecord{
+		Action: res.Action.String(),
+		Status: "ok",
+	}
+
+	if res.Src != nil {
+		rec.Src = res.Src.String()
+	}
+
+	if res.Dst != nil {
+		rec.Dst = res.Dst.String()
+	}
+
+	switch {
+	case res.Err != nil:
+		rec.Status = "failed"
+		rec.Error = res.Err.Error()
+	case dryRun:
+		rec.Status = "planned"
+	}
+
+	return &rec
+}
+
+func (r *SyncRecord) Text() string {
+	var fields []string
+
+	switch r.Status {
+	case "planned":
+		fields = append(fields, "(dry-run)")
+	case "failed":
+		fields = append(fields, "FAILED")
+	}
+
+	fields = append(fields, r.Action)
+
+	if len(r.Src) > 0 {
+		fields = append(fields, r.Src)
+	}
+
+	fields = append(fields, r.Dst)
+
+	if len(r.Error) > 0 {
+		fields = append(fields, r.Error)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func (r *SyncRecord) CSVHeader() []string {
+	return []string{"action", "src", "dst", "status", "error"}
+}
+
+func (r *SyncRecord) CSVRow() []string {
+	return []string{r.Action, r.Src, r.Dst, r.Status, r.Error}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatOptionalSize(size *uint64) string {
+	if size == nil {
+		return ""
+	}
+
+	return strconv.FormatUint(*size, 10)
+}
+
+// StatRecord carries the same fields as ObjectRecord with a detailed text
+// rendering.
+type StatRecord struct {
+	ObjectRecord
+}
+
+func NewStatRecord(u *url.URL, md *types.ObjectMetadata) *StatRecord {
+	return &StatRecord{ObjectRecord: *NewObjectRecord(&types.Object{URL: u, Metadata: md})}
+}
+
+func (r *StatRecord) Text() string {
+	return strings.Join([]string{
+		"url: " + r.URL,
+		fmt.Sprintf("size: %d (%s)", r.Size, humanize.IBytes(r.Size)),
+		"modification_date: " + formatTime(r.ModificationDate),
+		"etag: " + r.ETag,
+	}, "\n")
+}
+
+// TransferRecord reports the outcome of a copy or move of a single object.
+type TransferRecord struct {
+	Src string `json:"src"`
+	Dst string `json:"dst,omitempty"`
+	// Status is "ok" or "failed".
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewTransferRecord(src *url.URL, dst *url.URL, err error) *TransferRecord {
+	var res = TransferRecord{
+		Src:    src.String(),
+		Status: "ok",
+	}
+
+	if dst != nil {
+		res.Dst = dst.String()
+	}
+
+	if err != nil {
+		res.Status = "failed"
+		res.Error = err.Error()
+	}
+
+	return &res
+}
+
+func (r *TransferRecord) Text() string {
+	if r.Status == "failed" {
+		return strings.Join([]string{"FAILED", r.Src, r.Dst, r.Error}, " ")
+	}
+
+	return strings.Join([]string{"from", r.Src, "to", r.Dst}, " ")
+}
+
+func (r *TransferRecord) CSVHeader() []string {
+	return []string{"src", "dst", "status", "error"}
+}
+
+func (r *TransferRecord) CSVRow() []string {
+	return []string{r.Src, r.Dst, r.Status, r.Error}
+}
+
+// VerifyRecord reports the check of a single object, Status being "ok",
+// "missing", "mismatch" or "error".
+type VerifyRecord struct {
+	Path     string `json:"path"`
+	Left     string `json:"left,omitempty"`
+	Right    string `json:"right,omitempty"`
+	Status   string `json:"status"`
+	Checksum string `json:"checksum,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+func (r *VerifyRecord) Text() string {
+	var fields = []string{strings.ToUpper(r.Status), r.Path}
+
+	if len(r.Detail) > 0 {
+		fields = append(fields, r.Detail)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func (r *VerifyRecord) CSVHeader() []string {
+	return []string{"path", "left", "right", "status", "checksum", "detail"}
+}
+
+func (r *VerifyRecord) CSVRow() []string {
+	return []string{r.Path, r.Left, r.Right, r.Status, r.Checksum, r.Detail}
+}
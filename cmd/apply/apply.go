@@ -0,0 +1,73 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "apply",
+		Usage: "<plan.json>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.BoolFlag{Name: "dry-run", Usage: "print the operations without executing them"},
+			&cli.StringFlag{Name: "max-delete", Usage: "abort when more objects would be deleted, as a count (100) or a percentage (10%), on top of the limits recorded in the plan"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store  = objstr.FromContextOrDefault(ctx.Context)
+				dryRun = ctx.Bool("dry-run")
+			)
+
+			if ctx.Args().Len() != 1 {
+				return fmt.Errorf("expected a plan file")
+			}
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			maxDelete, maxDeletePercent, err := objstrcli.ParseMaxDelete(ctx.String("max-delete"))
+
+			if err != nil {
+				return err
+			}
+
+			content, err := os.ReadFile(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			var plan objstr.SyncPlan
+
+			if err := json.Unmarshal(content, &plan); err != nil {
+				return err
+			}
+
+			var applyErr = store.ApplySyncPlan(ctx.Context, &plan, &objstr.SyncOptions{
+				MaxConcurrentRequests: ctx.Int("max-concurrent-requests"),
+				MaxDelete:             maxDelete,
+				MaxDeletePercent:      maxDeletePercent,
+				DryRun:                dryRun,
+				OnResult: func(res objstr.SyncResult) {
+					printer.Print(objstrcli.NewSyncRecord(res, dryRun))
+				},
+			})
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			return applyErr
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/archive"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "archive",
+		Usage: "<prefix> <dst-object>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Usage: "tar, tar.gz, tar.zst or zip, defaults to a guess from the destination extension"},
+			&cli.BoolFlag{Name: "verbose"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var store = objstr.FromContextOrDefault(ctx.Context)
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			dst, err := url.Parse(ctx.Args().Get(1))
+
+			if err != nil {
+				return err
+			}
+
+			format, err := Format(ctx.String("format"), dst)
+
+			if err != nil {
+				return err
+			}
+
+			var opts = archive.Options{Format: format}
+
+			if ctx.Bool("verbose") {
+				opts.OnEntry = func(name string, _ uint64) {
+					fmt.Println(name)
+				}
+			}
+
+			return archive.Create(ctx.Context, store, prefix, dst, opts)
+		},
+	}
+}
+
+// Format parses the format flag, falling back to the extension of the
+// archive object.
+func Format(s string, u *url.URL) (archive.Format, error) {
+	if len(s) > 0 {
+		return archive.ParseFormat(s)
+	}
+
+	return archive.FormatFromPath(u.Path)
+}
@@ -0,0 +1,291 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sourcegraph/conc/iter"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "bench",
+		Usage: "<prefix>",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{Name: "size", Value: cli.NewStringSlice("4KiB", "1MiB", "16MiB"), Usage: "object size, can be repeated"},
+			&cli.IntFlag{Name: "count", Value: 16, Usage: "number of objects per size"},
+			&cli.IntFlag{Name: "concurrency", Value: 8, Usage: "number of concurrent operations"},
+			&cli.StringFlag{Name: "range-size", Value: "64KiB", Usage: "length of range reads"},
+			&cli.IntFlag{Name: "list-count", Value: 8, Usage: "number of listings"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store       = objstr.FromContextOrDefault(ctx.Context)
+				count       = ctx.Int("count")
+				concurrency = ctx.Int("concurrency")
+				listCount   = ctx.Int("list-count")
+				sizes       []uint64
+			)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			for _, s := range ctx.StringSlice("size") {
+				size, err := humanize.ParseBytes(s)
+
+				if err != nil {
+					return err
+				}
+
+				sizes = append(sizes, size)
+			}
+
+			rangeSize, err := humanize.ParseBytes(ctx.String("range-size"))
+
+			if err != nil {
+				return err
+			}
+
+			var b = bench{
+				store:       store,
+				root:        prefix.JoinPath("objstr-bench-"+uuid.Must(uuid.NewV7()).String(), "/"),
+				count:       count,
+				concurrency: concurrency,
+				rangeSize:   rangeSize,
+				data:        randomData(slices.Max(sizes)),
+			}
+
+			defer b.cleanup()
+
+			// stopped before cleanup runs, so that a second interrupt can
+			// still abort it
+			runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var printResult = func(r *result) error {
+				if runCtx.Err() != nil {
+					return fmt.Errorf("benchmark interrupted")
+				}
+
+				return printer.Print(r)
+			}
+
+			for _, size := range sizes {
+				for _, phase := range []func(context.Context, uint64) *result{b.write, b.read, b.rangeRead} {
+					if err := printResult(phase(runCtx, size)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := printResult(b.list(runCtx, listCount)); err != nil {
+				return err
+			}
+
+			for _, size := range sizes {
+				if err := printResult(b.delete(runCtx, size)); err != nil {
+					return err
+				}
+			}
+
+			return printer.Flush()
+		},
+	}
+}
+
+// bench writes its objects under a unique root so that listings only see
+// them and so that they can be removed whatever the outcome of the run.
+type bench struct {
+	store       *objstr.ObjectStore
+	root        *url.URL
+	count       int
+	concurrency int
+	rangeSize   uint64
+	data        []byte
+}
+
+func randomData(size uint64) []byte {
+	var (
+		res = make([]byte, size)
+		rng = rand.NewChaCha8([32]byte{})
+	)
+
+	rng.Read(res)
+	return res
+}
+
+func (b *bench) objectURL(size uint64, i int) *url.URL {
+	return b.root.JoinPath(strconv.FormatUint(size, 10), strconv.Itoa(i))
+}
+
+// run executes fn count times with bounded concurrency; fn returns the
+// number of bytes transferred by the operation.
+func (b *bench) run(phase string, size uint64, count int, fn func(i int) (uint64, error)) *result {
+	var (
+		s      stats
+		mapper = iter.Mapper[int, struct{}]{MaxGoroutines: b.concurrency}
+		start  = time.Now()
+	)
+
+	var idx = make([]int, count)
+
+	for i := range idx {
+		idx[i] = i
+	}
+
+	mapper.Map(idx, func(i *int) struct{} {
+		var opStart = time.Now()
+
+		n, err := fn(*i)
+
+		if err != nil {
+			slog.Debug("bench operation failed", "phase", phase, "error", err.Error())
+		}
+
+		s.record(time.Since(opStart), n, err)
+		return struct{}{}
+	})
+
+	return s.result(phase, size, time.Since(start))
+}
+
+func (b *bench) each(phase string, size uint64, fn func(u *url.URL) (uint64, error)) *result {
+	return b.run(phase, size, b.count, func(i int) (uint64, error) {
+		return fn(b.objectURL(size, i))
+	})
+}
+
+func (b *bench) write(ctx context.Context, size uint64) *result {
+	return b.each("write", size, func(u *url.URL) (uint64, error) {
+		w, err := b.store.Writer(ctx, u)
+
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := io.Copy(w, bytes.NewReader(b.data[:size]))
+
+		if err != nil {
+			w.Abort()
+			return 0, err
+		}
+
+		return uint64(n), w.Close()
+	})
+}
+
+func (b *bench) read(ctx context.Context, size uint64) *result {
+	return b.each("read", size, func(u *url.URL) (uint64, error) {
+		r, err := b.store.Reader(ctx, u)
+
+		if err != nil {
+			return 0, err
+		}
+
+		defer r.Close()
+
+		n, err := io.Copy(io.Discard, r)
+		return uint64(n), err
+	})
+}
+
+func (b *bench) rangeRead(ctx context.Context, size uint64) *result {
+	var length = min(b.rangeSize, size)
+
+	return b.each("range-read", size, func(u *url.URL) (uint64, error) {
+		var offset uint64
+
+		if size > length {
+			offset = rand.Uint64N(size - length)
+		}
+
+		r, err := b.store.RangeReader(ctx, u, int64(offset), int64(length))
+
+		if err != nil {
+			return 0, err
+		}
+
+		defer r.Close()
+
+		n, err := io.Copy(io.Discard, r)
+
+		if err == nil && uint64(n) != length {
+			err = fmt.Errorf("short range read: %d bytes instead of %d", n, length)
+		}
+
+		return uint64(n), err
+	})
+}
+
+func (b *bench) list(ctx context.Context, count int) *result {
+	return b.run("list", 0, count, func(int) (uint64, error) {
+		_, err := b.store.ListPrefix(ctx, b.root)
+		return 0, err
+	})
+}
+
+func (b *bench) delete(ctx context.Context, size uint64) *result {
+	return b.each("delete", size, func(u *url.URL) (uint64, error) {
+		return 0, b.store.Delete(ctx, u)
+	})
+}
+
+// cleanup removes whatever is left under the root, using a fresh context so
+// that it also runs after the benchmark was interrupted. On the fs backend,
+// the directories created for the objects are removed as well.
+func (b *bench) cleanup() {
+	var ctx = context.Background()
+
+	objects, err := b.store.ListPrefix(ctx, b.root)
+
+	if err != nil {
+		slog.Warn("failed to list benchmark objects", "prefix", b.root.String(), "error", err.Error())
+		return
+	}
+
+	var res *multierror.Error
+
+	for _, obj := range objects {
+		if err := b.store.Delete(ctx, obj.URL); err != nil {
+			res = multierror.Append(res, err)
+		}
+	}
+
+	if err := res.ErrorOrNil(); err != nil {
+		slog.Warn("failed to remove benchmark objects", "prefix", b.root.String(), "error", err.Error())
+		return
+	}
+
+	if b.root.Scheme == "file" {
+		if err := os.RemoveAll(filepath.Join(b.root.Host, filepath.FromSlash(b.root.Path))); err != nil {
+			slog.Warn("failed to remove benchmark directories", "prefix", b.root.String(), "error", err.Error())
+		}
+	}
+}
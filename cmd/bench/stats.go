@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// stats accumulates the outcome of the operations of a benchmark phase.
+type stats struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+	bytes     uint64
+	errors    int
+}
+
+func (s *stats) record(latency time.Duration, n uint64, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err != nil {
+		s.errors++
+		return
+	}
+
+	s.latencies = append(s.latencies, latency)
+	s.bytes += n
+}
+
+func (s *stats) result(phase string, size uint64, elapsed time.Duration) *result {
+	slices.Sort(s.latencies)
+
+	var res = result{
+		Phase:   phase,
+		Size:    size,
+		Ops:     len(s.latencies),
+		Errors:  s.errors,
+		Bytes:   s.bytes,
+		Elapsed: elapsed,
+		P50:     percentile(s.latencies, 50),
+		P90:     percentile(s.latencies, 90),
+		P99:     percentile(s.latencies, 99),
+	}
+
+	if len(s.latencies) > 0 {
+		res.Max = s.latencies[len(s.latencies)-1]
+	}
+
+	if elapsed > 0 {
+		res.OpsPerSecond = float64(res.Ops) / elapsed.Seconds()
+		res.BytesPerSecond = float64(res.Bytes) / elapsed.Seconds()
+	}
+
+	return &res
+}
+
+// percentile uses the nearest-rank method on sorted latencies.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	var rank = (p*len(latencies) + 99) / 100
+
+	return latencies[max(rank, 1)-1]
+}
+
+type result struct {
+	Phase          string        `json:"phase"`
+	Size           uint64        `json:"size"`
+	Ops            int           `json:"ops"`
+	Errors         int           `json:"errors"`
+	Bytes          uint64        `json:"bytes"`
+	Elapsed        time.Duration `json:"elapsed"`
+	OpsPerSecond   float64       `json:"ops_per_second"`
+	BytesPerSecond float64       `json:"bytes_per_second"`
+	P50            time.Duration `json:"p50"`
+	P90            time.Duration `json:"p90"`
+	P99            time.Duration `json:"p99"`
+	Max            time.Duration `json:"max"`
+}
+
+func (r *result) Text() string {
+	return fmt.Sprintf(
+		"%-10s %10s %6d ops %4d errors %10.1f ops/s %12s/s  p50 %-10s p90 %-10s p99 %-10s max %s",
+		r.Phase,
+		humanize.IBytes(r.Size),
+		r.Ops,
+		r.Errors,
+		r.OpsPerSecond,
+		humanize.IBytes(uint64(r.BytesPerSecond)),
+		r.P50.Round(time.Microsecond),
+		r.P90.Round(time.Microsecond),
+		r.P99.Round(time.Microsecond),
+		r.Max.Round(time.Microsecond),
+	)
+}
+
+func (r *result) CSVHeader() []string {
+	return []string{
+		"phase", "size", "ops", "errors", "bytes", "elapsed",
+		"ops_per_second", "bytes_per_second", "p50", "p90", "p99", "max",
+	}
+}
+
+func (r *result) CSVRow() []string {
+	return []string{
+		r.Phase,
+		strconv.FormatUint(r.Size, 10),
+		strconv.Itoa(r.Ops),
+		strconv.Itoa(r.Errors),
+		strconv.FormatUint(r.Bytes, 10),
+		r.Elapsed.String(),
+		strconv.FormatFloat(r.OpsPerSecond, 'f', 2, 64),
+		strconv.FormatFloat(r.BytesPerSecond, 'f', 0, 64),
+		r.P50.String(),
+		r.P90.String(),
+		r.P99.String(),
+		r.Max.String(),
+	}
+}
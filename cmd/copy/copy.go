@@ -2,26 +2,63 @@ package copy
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/manifest"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
 	"github.com/urfave/cli/v2"
 )
 
+type copyJob struct {
+	src *url.URL
+	dst *url.URL
+}
+
 func Command() *cli.Command {
 	return &cli.Command{
 		Name:    "copy",
 		Aliases: []string{"cp"},
-		Usage:   "<src> <dst>",
+		Usage:   "<src> <dst> | --from-manifest <manifest> <dst>",
+		Flags: []cli.Flag{
+			objstrcli.GlobFlag(),
+			&cli.BoolFlag{Name: "resume"},
+			&cli.PathFlag{Name: "resume-state-dir", Value: filepath.Join(os.TempDir(), "objstr", "resume")},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: 100},
+			&cli.BoolFlag{Name: "verbose"},
+			&cli.StringFlag{Name: "from-manifest", Usage: "copy the objects listed in a manifest to the same keys under dst"},
+		},
 		Action: func(ctx *cli.Context) error {
-			var store = objstr.FromContextOrDefault(ctx.Context)
+			var (
+				store                 = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
+				verbose               = ctx.Bool("verbose")
+				fromManifest          = ctx.String("from-manifest")
+				arg                   = ctx.Args().Get(0)
+				opts                  []types.CopyOption
+				jobs                  []copyJob
+			)
 
-			src, err := url.Parse(ctx.Args().Get(0))
+			if ctx.Bool("resume") {
+				opts = append(opts, types.WithResumeStateDir(ctx.Path("resume-state-dir")))
+			}
 
-			if err != nil {
-				return err
+			if len(fromManifest) > 0 {
+				var err error
+
+				if jobs, err = manifestJobs(ctx.Context, store, fromManifest, arg); err != nil {
+					return err
+				}
+
+				return copyAll(ctx.Context, store, jobs, maxConcurrentRequests, verbose, opts)
 			}
 
 			dst, err := url.Parse(ctx.Args().Get(1))
@@ -30,15 +67,96 @@ func Command() *cli.Command {
 				return err
 			}
 
-			if strings.HasSuffix(dst.Path, "/") {
-				dst.Path = path.Join(dst.Path, path.Base(src.Path))
+			if !objstr.IsGlobURL(arg, ctx.Bool("glob")) {
+				src, err := objstr.ParseLiteralURL(arg)
+
+				if err != nil {
+					return err
+				}
+
+				if strings.HasSuffix(dst.Path, "/") {
+					dst.Path = path.Join(dst.Path, path.Base(src.Path))
+				}
+
+				if err := store.Copy(ctx.Context, src, dst, opts...); err != nil {
+					return err
+				}
+
+				return nil
+			}
+
+			// every object matching the pattern is copied into dst, which is
+			// used as a directory, keeping its path relative to the literal
+			// directory of the pattern
+			objects, err := store.Glob(ctx.Context, arg)
+
+			if err != nil {
+				return err
 			}
 
-			if err := store.Copy(context.Background(), src, dst); err != nil {
+			dsts, err := store.GlobDestinations(arg, objects, dst)
+
+			if err != nil {
 				return err
 			}
 
-			return nil
+			for i, obj := range objects {
+				jobs = append(jobs, copyJob{src: obj.URL, dst: dsts[i]})
+			}
+
+			return copyAll(ctx.Context, store, jobs, maxConcurrentRequests, verbose, opts)
 		},
 	}
 }
+
+func manifestJobs(ctx context.Context, store *objstr.ObjectStore, rawManifestURL string, rawDst string) ([]copyJob, error) {
+	manifestURL, err := url.Parse(rawManifestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err := url.Parse(rawDst)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := manifest.Load(ctx, store, manifestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []copyJob
+
+	for _, e := range entries {
+		src, err := e.ParseURL()
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, copyJob{src: src, dst: e.Destination(dst, src)})
+	}
+
+	return res, nil
+}
+
+func copyAll(ctx context.Context, store *objstr.ObjectStore, jobs []copyJob, maxConcurrentRequests int, verbose bool, opts []types.CopyOption) error {
+	var mapper = iter.Mapper[copyJob, error]{
+		MaxGoroutines: maxConcurrentRequests,
+	}
+
+	var errs = mapper.Map(jobs, func(job *copyJob) error {
+		var err = store.Copy(ctx, job.src, job.dst, opts...)
+
+		if err == nil && verbose {
+			fmt.Println(job.src.String(), "->", job.dst.String())
+		}
+
+		return err
+	})
+
+	return errors.Join(errs...)
+}
@@ -1,15 +1,10 @@
 package copyprefix
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"net/url"
 
 	"github.com/agnosticeng/objstr"
-	"github.com/agnosticeng/objstr/types"
-	"github.com/agnosticeng/objstr/utils"
-	"github.com/sourcegraph/conc/iter"
 	"github.com/urfave/cli/v2"
 )
 
@@ -19,7 +14,7 @@ func Command() *cli.Command {
 		Aliases: []string{"cpp", "cpr"},
 		Usage:   "<src> <dst>",
 		Flags: []cli.Flag{
-			&cli.IntFlag{Name: "max-concurrent-requests", Value: 100},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
 			&cli.BoolFlag{Name: "verbose"},
 		},
 		Action: func(ctx *cli.Context) error {
@@ -41,29 +36,14 @@ func Command() *cli.Command {
 				return err
 			}
 
-			objects, err := store.ListPrefix(context.Background(), srcPrefix)
-
-			if err != nil {
-				return err
-			}
-
-			var mapper = iter.Mapper[*types.Object, error]{
-				MaxGoroutines: maxConcurrentRequests,
-			}
-
-			var errs = mapper.Map(objects, func(o **types.Object) error {
-				var src = (*o).URL
-
-				dst, err := utils.GenerateDstURL(dstPrefix, srcPrefix, src)
-
-				if err == nil && verbose {
-					fmt.Println("from", src.String(), "to", dst.String())
-				}
-
-				return store.Copy(ctx.Context, src, dst)
+			return store.CopyPrefix(ctx.Context, srcPrefix, dstPrefix, &objstr.CopyPrefixOptions{
+				MaxConcurrentRequests: maxConcurrentRequests,
+				OnResult: func(res objstr.CopyResult) {
+					if res.Err == nil && verbose {
+						fmt.Println("from", res.Src.String(), "to", res.Dst.String())
+					}
+				},
 			})
-
-			return errors.Join(errs...)
 		},
 	}
 }
@@ -1,13 +1,11 @@
 package diff
 
 import (
-	"context"
 	"fmt"
 	"net/url"
 
 	"github.com/agnosticeng/objstr"
-	"github.com/agnosticeng/objstr/types"
-	"github.com/agnosticeng/objstr/utils"
+	objstrcli "github.com/agnosticeng/objstr/cli"
 	"github.com/dustin/go-humanize"
 	"github.com/urfave/cli/v2"
 )
@@ -16,80 +14,73 @@ func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "diff",
 		Usage: "<left> <right>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.StringFlag{
+				Name:  "compare",
+				Value: string(objstr.CompareSize),
+				Usage: "comparison of objects present on both sides: size, size-mtime, etag or hash",
+			},
+		},
 		Action: func(ctx *cli.Context) error {
 			var (
-				os   = objstr.FromContextOrDefault(ctx.Context)
-				opts []types.ListOption
+				os                    = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
 			)
 
-			leftU, err := url.Parse(ctx.Args().Get(0))
+			printer, err := objstrcli.NewPrinter(ctx)
 
 			if err != nil {
 				return err
 			}
 
-			rightU, err := url.Parse(ctx.Args().Get(1))
+			compareMode, err := objstr.ParseCompareMode(ctx.String("compare"))
 
 			if err != nil {
 				return err
 			}
 
-			leftObjs, err := os.ListPrefix(context.Background(), leftU, opts...)
+			leftU, err := url.Parse(ctx.Args().Get(0))
 
 			if err != nil {
 				return err
 			}
 
-			rightObjs, err := os.ListPrefix(context.Background(), rightU, opts...)
+			rightU, err := url.Parse(ctx.Args().Get(1))
 
 			if err != nil {
 				return err
 			}
 
-			var pairs = utils.Associate(
-				leftU,
-				leftObjs,
-				rightU,
-				rightObjs,
-			)
+			res, err := os.Diff(ctx.Context, leftU, rightU, &objstr.DiffOptions{
+				CompareMode:           compareMode,
+				MaxConcurrentRequests: maxConcurrentRequests,
+			})
 
-			for _, pair := range pairs {
-				switch {
-				case pair.Right == nil:
-					fmt.Println("RIGHT MISSING", pair.Path)
-				case pair.Left == nil:
-					fmt.Println("LEFT MISSING", pair.Path)
-				case pair.Left.Metadata.Size != pair.Right.Metadata.Size:
-					fmt.Println(
-						"SIZE DIFFERS", pair.Path,
-						"LEFT", humanize.Bytes(pair.Left.Metadata.Size),
-						"RIGHT", humanize.Bytes(pair.Right.Metadata.Size),
-					)
-				}
+			if err != nil {
+				return err
 			}
 
-			var (
-				leftFiles  uint64
-				rightFiles uint64
-				leftSize   uint64
-				rightSize  uint64
-			)
-
-			for _, pair := range pairs {
-				if pair.Left != nil {
-					leftFiles++
-					leftSize += pair.Left.Metadata.Size
+			for _, diff := range res.Differences {
+				if err := printer.Print(objstrcli.NewDiffRecord(diff)); err != nil {
+					return err
 				}
+			}
 
-				if pair.Right != nil {
-					rightFiles++
-					rightSize += pair.Right.Metadata.Size
-				}
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			if printer.IsText() {
+				fmt.Println()
+				fmt.Println("files", res.LeftFiles, res.RightFiles)
+				fmt.Println("sizes", humanize.Bytes(res.LeftSize), humanize.Bytes(res.RightSize))
 			}
 
-			fmt.Println()
-			fmt.Println("files", leftFiles, rightFiles)
-			fmt.Println("sizes", humanize.Bytes(leftSize), humanize.Bytes(rightSize))
+			// a non-zero exit code lets diff be used as a check
+			if len(res.Differences) > 0 {
+				return fmt.Errorf("%d differences found", len(res.Differences))
+			}
 
 			return nil
 		},
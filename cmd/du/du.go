@@ -0,0 +1,179 @@
+package du
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/dustin/go-humanize"
+	"github.com/urfave/cli/v2"
+)
+
+type usage struct {
+	Prefix  string `json:"prefix"`
+	Objects uint64 `json:"objects"`
+	Size    uint64 `json:"size"`
+}
+
+func (u *usage) Text() string {
+	return fmt.Sprintf("%-10s %10d  %s", humanize.Bytes(u.Size), u.Objects, u.Prefix)
+}
+
+func (u *usage) CSVHeader() []string {
+	return []string{"prefix", "objects", "size"}
+}
+
+func (u *usage) CSVRow() []string {
+	return []string{u.Prefix, strconv.FormatUint(u.Objects, 10), strconv.FormatUint(u.Size, 10)}
+}
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "du",
+		Usage: "<prefix>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "depth", Value: 1, Usage: "number of path segments below the prefix to group by"},
+			&cli.StringFlag{Name: "sort", Value: "size", Usage: "size, objects or name"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store  = objstr.FromContextOrDefault(ctx.Context)
+				depth  = ctx.Int("depth")
+				sortBy = ctx.String("sort")
+				groups = make(map[string]*usage)
+				total  usage
+			)
+
+			if depth < 0 {
+				return fmt.Errorf("depth must not be negative: %d", depth)
+			}
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			// object paths are made relative to the prefix in the form the
+			// backend lists them
+			if prefix, err = store.ResolvePrefix(prefix); err != nil {
+				return err
+			}
+
+			objects, err := store.ListPrefix(ctx.Context, prefix)
+
+			if err != nil {
+				return err
+			}
+
+			for _, obj := range objects {
+				var (
+					base = prefix.Path
+					rel  = strings.TrimPrefix(obj.URL.Path, prefix.Path)
+				)
+
+				// a prefix without trailing slash is grouped as a directory
+				if strings.HasPrefix(rel, "/") {
+					base, rel = base+"/", rel[1:]
+				}
+
+				var key = base + groupKey(rel, depth)
+
+				g, found := groups[key]
+
+				if !found {
+					var u = *prefix
+					u.Path = key
+					g = &usage{Prefix: u.String()}
+					groups[key] = g
+				}
+
+				g.Objects++
+				g.Size += obj.Metadata.Size
+				total.Objects++
+				total.Size += obj.Metadata.Size
+			}
+
+			var res []*usage
+
+			for _, g := range groups {
+				res = append(res, g)
+			}
+
+			if err := sortUsages(res, sortBy); err != nil {
+				return err
+			}
+
+			for _, g := range res {
+				if err := printer.Print(g); err != nil {
+					return err
+				}
+			}
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			if printer.IsText() {
+				fmt.Println()
+				fmt.Println("size", humanize.Bytes(total.Size), "files", total.Objects)
+			}
+
+			return nil
+		},
+	}
+}
+
+// groupKey keeps the first depth directory segments of a relative path;
+// objects shallower than depth are grouped with their own directory.
+func groupKey(rel string, depth int) string {
+	var (
+		segments = strings.Split(rel, "/")
+		dirs     = segments[:len(segments)-1]
+	)
+
+	if len(dirs) > depth {
+		dirs = dirs[:depth]
+	}
+
+	if len(dirs) == 0 {
+		return ""
+	}
+
+	return strings.Join(dirs, "/") + "/"
+}
+
+func sortUsages(usages []*usage, sortBy string) error {
+	var less func(i, j int) bool
+
+	switch sortBy {
+	case "size":
+		less = func(i, j int) bool { return usages[i].Size > usages[j].Size }
+	case "objects":
+		less = func(i, j int) bool { return usages[i].Objects > usages[j].Objects }
+	case "name":
+		less = func(i, j int) bool { return usages[i].Prefix < usages[j].Prefix }
+	default:
+		return fmt.Errorf("unknown sort order: %s", sortBy)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		if less(i, j) == less(j, i) {
+			return usages[i].Prefix < usages[j].Prefix
+		}
+
+		return less(i, j)
+	})
+
+	return nil
+}
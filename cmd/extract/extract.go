@@ -0,0 +1,53 @@
+package extract
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/archive"
+	archivecmd "github.com/agnosticeng/objstr/cmd/archive"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "extract",
+		Usage: "<archive-object> <dst-prefix>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Usage: "tar, tar.gz, tar.zst or zip, defaults to a guess from the archive extension"},
+			&cli.BoolFlag{Name: "verbose"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var store = objstr.FromContextOrDefault(ctx.Context)
+
+			src, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			dstPrefix, err := url.Parse(ctx.Args().Get(1))
+
+			if err != nil {
+				return err
+			}
+
+			format, err := archivecmd.Format(ctx.String("format"), src)
+
+			if err != nil {
+				return err
+			}
+
+			var opts = archive.Options{Format: format}
+
+			if ctx.Bool("verbose") {
+				opts.OnEntry = func(name string, _ uint64) {
+					fmt.Println(name)
+				}
+			}
+
+			return archive.Extract(ctx.Context, store, src, dstPrefix, opts)
+		},
+	}
+}
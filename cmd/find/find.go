@@ -0,0 +1,173 @@
+package find
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "find",
+		Usage: "<prefix>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Usage: "glob matched against the object base name"},
+			&cli.StringFlag{Name: "regex", Usage: "regular expression matched against the path relative to the prefix"},
+			&cli.StringSliceFlag{Name: "size", Usage: "+N (more than), -N (less than) or N bytes, with optional unit, can be repeated"},
+			&cli.StringFlag{Name: "modified-after", Usage: "RFC3339 time, date or duration before now"},
+			&cli.StringFlag{Name: "modified-before", Usage: "RFC3339 time, date or duration before now"},
+			&cli.StringFlag{Name: "etag"},
+			&cli.BoolFlag{Name: "print", Usage: "print matching objects, the default action"},
+			&cli.BoolFlag{Name: "print0", Usage: "print matching URLs separated by NUL characters"},
+			&cli.BoolFlag{Name: "delete", Usage: "delete matching objects"},
+			&cli.StringFlag{Name: "copy-to", Usage: "copy matching objects to the same relative path under this prefix"},
+			&cli.StringFlag{Name: "exec", Usage: "command run for each matching object, {} being replaced by its URL"},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store                 = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
+				exe                   = strings.Fields(ctx.String("exec"))
+				print0                = ctx.Bool("print0")
+				matches               []*types.Object
+				copyTo                *url.URL
+			)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			predicates, err := buildPredicates(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			if s := ctx.String("copy-to"); len(s) > 0 {
+				if copyTo, err = url.Parse(s); err != nil {
+					return err
+				}
+			}
+
+			var printMatches = ctx.Bool("print") || (!print0 && !ctx.Bool("delete") && copyTo == nil && len(exe) == 0)
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			// predicates match and --copy-to keys use the path relative to
+			// the prefix in the form the backend lists objects
+			if prefix, err = store.ResolvePrefix(prefix); err != nil {
+				return err
+			}
+
+			objects, err := store.ListPrefix(ctx.Context, prefix)
+
+			if err != nil {
+				return err
+			}
+
+			for _, obj := range objects {
+				if matchAll(predicates, strings.TrimPrefix(strings.TrimPrefix(obj.URL.Path, prefix.Path), "/"), obj) {
+					matches = append(matches, obj)
+				}
+			}
+
+			for _, obj := range matches {
+				switch {
+				case print0:
+					fmt.Print(obj.URL.String() + "\x00")
+				case printMatches:
+					if err := printer.Print(objstrcli.NewObjectRecord(obj)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			var mapper = iter.Mapper[*types.Object, error]{
+				MaxGoroutines: maxConcurrentRequests,
+			}
+
+			// actions run in the order given by GNU find: the object is
+			// deleted last so that it can be copied or used by the command
+			var errs = mapper.Map(matches, func(o **types.Object) error {
+				var obj = *o
+
+				if copyTo != nil {
+					dst, err := types.GenerateDstURL(copyTo, prefix, obj.URL)
+
+					if err != nil {
+						return err
+					}
+
+					if err := store.Copy(ctx.Context, obj.URL, dst); err != nil {
+						return err
+					}
+				}
+
+				if len(exe) > 0 {
+					if err := run(ctx.Context, exe, obj.URL); err != nil {
+						return err
+					}
+				}
+
+				if ctx.Bool("delete") {
+					return store.Delete(ctx.Context, obj.URL)
+				}
+
+				return nil
+			})
+
+			return errors.Join(errs...)
+		},
+	}
+}
+
+func matchAll(predicates []predicate, rel string, obj *types.Object) bool {
+	for _, p := range predicates {
+		if !p(rel, obj) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// run executes the command without a shell, {} arguments are replaced by the
+// object URL so keys with spaces stay a single argument.
+func run(ctx context.Context, exe []string, u *url.URL) error {
+	var args = make([]string, len(exe))
+
+	for i, arg := range exe {
+		args[i] = strings.ReplaceAll(arg, "{}", u.String())
+	}
+
+	var cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", u.String(), err)
+	}
+
+	return nil
+}
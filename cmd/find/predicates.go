@@ -0,0 +1,131 @@
+package find
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/dustin/go-humanize"
+	"github.com/urfave/cli/v2"
+)
+
+type predicate func(rel string, obj *types.Object) bool
+
+func buildPredicates(ctx *cli.Context) ([]predicate, error) {
+	var res []predicate
+
+	if pattern := ctx.String("name"); len(pattern) > 0 {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+
+		res = append(res, func(_ string, obj *types.Object) bool {
+			matched, _ := path.Match(pattern, path.Base(obj.URL.Path))
+			return matched
+		})
+	}
+
+	if expr := ctx.String("regex"); len(expr) > 0 {
+		re, err := regexp.Compile(expr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, func(rel string, _ *types.Object) bool {
+			return re.MatchString(rel)
+		})
+	}
+
+	for _, s := range ctx.StringSlice("size") {
+		p, err := sizePredicate(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, p)
+	}
+
+	if s := ctx.String("modified-after"); len(s) > 0 {
+		t, err := parseTime(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, func(_ string, obj *types.Object) bool {
+			return obj.Metadata.ModificationDate.After(t)
+		})
+	}
+
+	if s := ctx.String("modified-before"); len(s) > 0 {
+		t, err := parseTime(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, func(_ string, obj *types.Object) bool {
+			return obj.Metadata.ModificationDate.Before(t)
+		})
+	}
+
+	if etag := ctx.String("etag"); len(etag) > 0 {
+		res = append(res, func(_ string, obj *types.Object) bool {
+			return strings.Trim(obj.Metadata.ETag, "\"") == strings.Trim(etag, "\"")
+		})
+	}
+
+	return res, nil
+}
+
+// sizePredicate parses GNU find style sizes: +N for more than N bytes, -N
+// for less than N bytes and N for exactly N bytes, N accepting units such as
+// 10MB or 1GiB.
+func sizePredicate(s string) (predicate, error) {
+	var (
+		op  = s[:min(1, len(s))]
+		raw = s
+	)
+
+	if op == "+" || op == "-" {
+		raw = s[1:]
+	}
+
+	size, err := humanize.ParseBytes(raw)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid size: %s", s)
+	}
+
+	switch op {
+	case "+":
+		return func(_ string, obj *types.Object) bool { return obj.Metadata.Size > size }, nil
+	case "-":
+		return func(_ string, obj *types.Object) bool { return obj.Metadata.Size < size }, nil
+	default:
+		return func(_ string, obj *types.Object) bool { return obj.Metadata.Size == size }, nil
+	}
+}
+
+// parseTime accepts RFC3339 timestamps, dates, and durations counted back
+// from now.
+func parseTime(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
@@ -0,0 +1,79 @@
+package head
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "head",
+		Usage: "<src>",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "bytes", Aliases: []string{"c"}, Value: -1, Usage: "print the first N bytes"},
+			&cli.IntFlag{Name: "lines", Aliases: []string{"n"}, Value: 10, Usage: "print the first N lines"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store = objstr.FromContextOrDefault(ctx.Context)
+				bytes = ctx.Int64("bytes")
+				lines = ctx.Int("lines")
+			)
+
+			src, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			if bytes >= 0 {
+				r, err := store.RangeReader(ctx.Context, src, 0, bytes)
+
+				if err != nil {
+					return err
+				}
+
+				defer r.Close()
+
+				_, err = io.Copy(os.Stdout, r)
+				return err
+			}
+
+			r, err := store.Reader(ctx.Context, src)
+
+			if err != nil {
+				return err
+			}
+
+			// the reader is closed as soon as enough lines are read, so that
+			// the rest of the object is never downloaded
+			defer r.Close()
+
+			var br = bufio.NewReader(r)
+
+			for i := 0; i < lines; i++ {
+				line, err := br.ReadBytes('\n')
+
+				if _, err := os.Stdout.Write(line); err != nil {
+					return err
+				}
+
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
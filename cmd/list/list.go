@@ -3,9 +3,9 @@ package list
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
 	"github.com/agnosticeng/objstr/types"
 	"github.com/dustin/go-humanize"
 	"github.com/urfave/cli/v2"
@@ -17,6 +17,7 @@ func Command() *cli.Command {
 		Aliases: []string{"ls"},
 		Usage:   "<prefix>",
 		Flags: []cli.Flag{
+			objstrcli.GlobFlag(),
 			&cli.StringFlag{Name: "start-after"},
 		},
 		Action: func(ctx *cli.Context) error {
@@ -26,7 +27,7 @@ func Command() *cli.Command {
 				opts       []types.ListOption
 			)
 
-			u, err := url.Parse(ctx.Args().Get(0))
+			printer, err := objstrcli.NewPrinter(ctx)
 
 			if err != nil {
 				return err
@@ -36,7 +37,7 @@ func Command() *cli.Command {
 				opts = append(opts, types.WithStartAfter(startAfter))
 			}
 
-			objects, err := os.ListPrefix(context.Background(), u, opts...)
+			objects, err := listObjects(ctx.Context, os, ctx.Args().Get(0), ctx.Bool("glob"), opts...)
 
 			if err != nil {
 				return err
@@ -45,19 +46,57 @@ func Command() *cli.Command {
 			var totalSize uint64
 
 			for _, object := range objects {
-				fmt.Println(
-					object.URL.String(),
-					humanize.Bytes(object.Metadata.Size),
-					object.Metadata.ModificationDate,
-					object.Metadata.ETag,
-				)
+				if err := printer.Print(objstrcli.NewObjectRecord(object)); err != nil {
+					return err
+				}
 
 				totalSize += object.Metadata.Size
 			}
 
-			fmt.Println()
-			fmt.Println("size", humanize.Bytes(totalSize), "files", len(objects))
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			if printer.IsText() {
+				fmt.Println()
+				fmt.Println("size", humanize.Bytes(totalSize), "files", len(objects))
+			}
+
 			return nil
 		},
 	}
 }
+
+func listObjects(ctx context.Context, os *objstr.ObjectStore, arg string, glob bool, opts ...types.ListOption) ([]*types.Object, error) {
+	if !objstr.IsGlobURL(arg, glob) {
+		u, err := objstr.ParseLiteralURL(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return os.ListPrefix(ctx, u, opts...)
+	}
+
+	objects, err := os.Glob(ctx, arg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var listOpts = types.NewListOptions(opts...)
+
+	if len(listOpts.StartAfter) == 0 {
+		return objects, nil
+	}
+
+	var res []*types.Object
+
+	for _, obj := range objects {
+		if obj.URL.String() > listOpts.StartAfter {
+			res = append(res, obj)
+		}
+	}
+
+	return res, nil
+}
@@ -8,14 +8,33 @@ import (
 	"github.com/agnosticeng/cnf"
 	"github.com/agnosticeng/cnf/providers/env"
 	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/cmd/apply"
+	"github.com/agnosticeng/objstr/cmd/archive"
+	"github.com/agnosticeng/objstr/cmd/bench"
 	"github.com/agnosticeng/objstr/cmd/copy"
 	"github.com/agnosticeng/objstr/cmd/copyprefix"
 	"github.com/agnosticeng/objstr/cmd/diff"
+	"github.com/agnosticeng/objstr/cmd/du"
+	"github.com/agnosticeng/objstr/cmd/extract"
+	"github.com/agnosticeng/objstr/cmd/find"
+	"github.com/agnosticeng/objstr/cmd/head"
 	"github.com/agnosticeng/objstr/cmd/list"
+	"github.com/agnosticeng/objstr/cmd/manifest"
+	"github.com/agnosticeng/objstr/cmd/move"
+	"github.com/agnosticeng/objstr/cmd/moveprefix"
 	"github.com/agnosticeng/objstr/cmd/read"
 	"github.com/agnosticeng/objstr/cmd/remove"
 	"github.com/agnosticeng/objstr/cmd/removeprefix"
+	"github.com/agnosticeng/objstr/cmd/s3server"
+	"github.com/agnosticeng/objstr/cmd/serve"
+	"github.com/agnosticeng/objstr/cmd/shell"
+	"github.com/agnosticeng/objstr/cmd/stat"
 	"github.com/agnosticeng/objstr/cmd/sync"
+	"github.com/agnosticeng/objstr/cmd/tail"
+	"github.com/agnosticeng/objstr/cmd/tree"
+	"github.com/agnosticeng/objstr/cmd/verify"
+	"github.com/agnosticeng/objstr/cmd/webdav"
+	"github.com/agnosticeng/objstr/cmd/write"
 	"github.com/agnosticeng/slogcli"
 	"github.com/urfave/cli/v2"
 )
@@ -31,16 +50,35 @@ func main() {
 			objstrcli.ObjStrAfter,
 			slogcli.SlogAfter,
 		),
-		Flags: slogcli.SlogFlags(),
+		Flags: append(slogcli.SlogFlags(), objstrcli.OutputFlags()...),
 		Commands: []*cli.Command{
 			list.Command(),
 			copy.Command(),
 			remove.Command(),
 			read.Command(),
+			write.Command(),
+			stat.Command(),
+			head.Command(),
+			tail.Command(),
+			du.Command(),
+			tree.Command(),
+			find.Command(),
+			manifest.Command(),
+			verify.Command(),
+			archive.Command(),
+			extract.Command(),
 			removeprefix.Command(),
 			copyprefix.Command(),
+			move.Command(),
+			moveprefix.Command(),
 			diff.Command(),
 			sync.Command(),
+			apply.Command(),
+			shell.Command(),
+			bench.Command(),
+			serve.Command(),
+			s3server.Command(),
+			webdav.Command(),
 		},
 	}
 	if err := app.Run(os.Args); err != nil {
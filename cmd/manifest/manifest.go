@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/manifest"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name: "manifest",
+		Subcommands: []*cli.Command{
+			exportCommand(),
+		},
+	}
+}
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "<prefix> [dst]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "format", Usage: "ndjson or csv, guessed from the destination extension by default"},
+			&cli.StringFlag{Name: "checksum", Usage: "also record a checksum computed with this algorithm: md5, sha1, sha256 or crc32c"},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store               = objstr.FromContextOrDefault(ctx.Context)
+				format              = ctx.String("format")
+				algorithm           = ctx.String("checksum")
+				out       io.Writer = os.Stdout
+			)
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			if prefix, err = store.ResolvePrefix(prefix); err != nil {
+				return err
+			}
+
+			objects, err := store.ListPrefix(ctx.Context, prefix)
+
+			if err != nil {
+				return err
+			}
+
+			var entries []*manifest.Entry
+
+			for _, obj := range objects {
+				entries = append(entries, manifest.NewEntry(prefix, obj))
+			}
+
+			if len(algorithm) > 0 {
+				var mapper = iter.Mapper[*manifest.Entry, error]{
+					MaxGoroutines: ctx.Int("max-concurrent-requests"),
+				}
+
+				var errs = mapper.Map(entries, func(e **manifest.Entry) error {
+					u, err := (*e).ParseURL()
+
+					if err != nil {
+						return err
+					}
+
+					sum, err := store.Checksum(ctx.Context, u, algorithm)
+
+					if err != nil {
+						return err
+					}
+
+					(*e).Checksum = algorithm + ":" + sum
+					return nil
+				})
+
+				if err := errors.Join(errs...); err != nil {
+					return err
+				}
+			}
+
+			var dstWriter types.Writer
+
+			if ctx.Args().Len() > 1 {
+				dst, err := url.Parse(ctx.Args().Get(1))
+
+				if err != nil {
+					return err
+				}
+
+				if len(format) == 0 {
+					format = manifest.FormatFromPath(dst.Path)
+				}
+
+				if dstWriter, err = store.Writer(ctx.Context, dst); err != nil {
+					return err
+				}
+
+				out = dstWriter
+			}
+
+			if len(format) == 0 {
+				format = manifest.FormatNDJSON
+			}
+
+			if err := writeEntries(out, format, entries); err != nil {
+				if dstWriter != nil {
+					dstWriter.Abort()
+				}
+
+				return err
+			}
+
+			if dstWriter != nil {
+				return dstWriter.Close()
+			}
+
+			return nil
+		},
+	}
+}
+
+func writeEntries(w io.Writer, format string, entries []*manifest.Entry) error {
+	mw, err := manifest.NewWriter(w, format)
+
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if err := mw.Write(e); err != nil {
+			return err
+		}
+	}
+
+	return mw.Flush()
+}
@@ -0,0 +1,117 @@
+package move
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/samber/lo"
+	"github.com/sourcegraph/conc/iter"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:    "move",
+		Aliases: []string{"mv"},
+		Usage:   "<src> <dst>",
+		Flags: []cli.Flag{
+			objstrcli.GlobFlag(),
+			objstrcli.VerifyFlag(),
+			&cli.BoolFlag{Name: "resume"},
+			&cli.PathFlag{Name: "resume-state-dir", Value: filepath.Join(os.TempDir(), "objstr", "resume")},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.BoolFlag{Name: "verbose"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store                 = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
+				verbose               = ctx.Bool("verbose")
+				arg                   = ctx.Args().Get(0)
+			)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			opts, err := objstrcli.VerifyOptions(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			if ctx.Bool("resume") {
+				opts = append(opts, types.WithResumeStateDir(ctx.Path("resume-state-dir")))
+			}
+
+			dst, err := url.Parse(ctx.Args().Get(1))
+
+			if err != nil {
+				return err
+			}
+
+			if !objstr.IsGlobURL(arg, ctx.Bool("glob")) {
+				src, err := objstr.ParseLiteralURL(arg)
+
+				if err != nil {
+					return err
+				}
+
+				if strings.HasSuffix(dst.Path, "/") {
+					dst.Path = path.Join(dst.Path, path.Base(src.Path))
+				}
+
+				return store.Move(ctx.Context, src, dst, opts...)
+			}
+
+			// every object matching the pattern is moved into dst, which is
+			// used as a directory, keeping its path relative to the literal
+			// directory of the pattern; destinations are all resolved before
+			// anything is moved so that a collision can't lose a source
+			objects, err := store.Glob(ctx.Context, arg)
+
+			if err != nil {
+				return err
+			}
+
+			dsts, err := store.GlobDestinations(arg, objects, dst)
+
+			if err != nil {
+				return err
+			}
+
+			var mapper = iter.Mapper[int, error]{
+				MaxGoroutines: maxConcurrentRequests,
+			}
+
+			var errs = mapper.Map(lo.Range(len(objects)), func(i *int) error {
+				var (
+					src    = objects[*i].URL
+					objDst = dsts[*i]
+					err    = store.Move(ctx.Context, src, objDst, opts...)
+				)
+
+				if err != nil || verbose || !printer.IsText() {
+					printer.Print(objstrcli.NewTransferRecord(src, objDst, err))
+				}
+
+				return err
+			})
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			return errors.Join(errs...)
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package moveprefix
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:    "moveprefix",
+		Aliases: []string{"mvp", "mvr"},
+		Usage:   "<src> <dst>",
+		Flags: []cli.Flag{
+			objstrcli.VerifyFlag(),
+			&cli.BoolFlag{Name: "resume"},
+			&cli.PathFlag{Name: "resume-state-dir", Value: filepath.Join(os.TempDir(), "objstr", "resume")},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.BoolFlag{Name: "verbose"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store                 = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
+				verbose               = ctx.Bool("verbose")
+				moved                 atomic.Int64
+				failed                atomic.Int64
+			)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			opts, err := objstrcli.VerifyOptions(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			if ctx.Bool("resume") {
+				opts = append(opts, types.WithResumeStateDir(ctx.Path("resume-state-dir")))
+			}
+
+			srcPrefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			dstPrefix, err := url.Parse(ctx.Args().Get(1))
+
+			if err != nil {
+				return err
+			}
+
+			var moveErr = store.MovePrefix(ctx.Context, srcPrefix, dstPrefix, &objstr.MovePrefixOptions{
+				MaxConcurrentRequests: maxConcurrentRequests,
+				CopyOptions:           opts,
+				OnResult: func(res objstr.MoveResult) {
+					if res.Err != nil {
+						failed.Add(1)
+					} else {
+						moved.Add(1)
+					}
+
+					if res.Err != nil || verbose || !printer.IsText() {
+						printer.Print(objstrcli.NewTransferRecord(res.Src, res.Dst, res.Err))
+					}
+				},
+			})
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			if printer.IsText() {
+				fmt.Println("moved", moved.Load(), "failed", failed.Load())
+			}
+
+			return moveErr
+		},
+	}
+}
@@ -1,12 +1,13 @@
 package read
 
 import (
-	"errors"
+	"context"
 	"io"
 	"net/url"
 	"os"
 
 	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
 	"github.com/urfave/cli/v2"
 )
 
@@ -14,40 +15,64 @@ func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "read",
 		Usage: "<src>",
+		Flags: []cli.Flag{
+			objstrcli.GlobFlag(),
+			&cli.Int64Flag{Name: "offset", Usage: "first byte to read"},
+			&cli.Int64Flag{Name: "length", Value: -1, Usage: "number of bytes to read, up to the end of the object when negative"},
+		},
 		Action: func(ctx *cli.Context) error {
-			var store = objstr.FromContextOrDefault(ctx.Context)
+			var (
+				store  = objstr.FromContextOrDefault(ctx.Context)
+				arg    = ctx.Args().Get(0)
+				offset = ctx.Int64("offset")
+				length = ctx.Int64("length")
+			)
 
-			src, err := url.Parse(ctx.Args().Get(0))
+			if !objstr.IsGlobURL(arg, ctx.Bool("glob")) {
+				src, err := objstr.ParseLiteralURL(arg)
 
-			if err != nil {
-				return err
+				if err != nil {
+					return err
+				}
+
+				return readObject(ctx.Context, store, src, offset, length)
 			}
 
-			r, err := store.Reader(ctx.Context, src)
+			objects, err := store.Glob(ctx.Context, arg)
 
 			if err != nil {
 				return err
 			}
 
-			for {
-				var buf = make([]byte, 1024*1024)
-
-				n, err := r.Read(buf)
-
-				if n > 0 {
-					if _, err := os.Stdout.Write(buf); err != nil {
-						return err
-					}
-				}
-
-				if errors.Is(err, io.EOF) {
-					return nil
-				}
-
-				if err != nil {
+			for _, obj := range objects {
+				if err := readObject(ctx.Context, store, obj.URL, offset, length); err != nil {
 					return err
 				}
 			}
+
+			return nil
 		},
 	}
 }
+
+func readObject(ctx context.Context, store *objstr.ObjectStore, src *url.URL, offset int64, length int64) error {
+	var (
+		r   io.ReadCloser
+		err error
+	)
+
+	if offset > 0 || length >= 0 {
+		r, err = store.RangeReader(ctx, src, offset, length)
+	} else {
+		r, err = store.Reader(ctx, src)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	_, err = io.CopyBuffer(os.Stdout, r, make([]byte, 1024*1024))
+	return err
+}
@@ -2,9 +2,15 @@ package remove
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 
 	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/manifest"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
 	"github.com/urfave/cli/v2"
 )
 
@@ -12,21 +18,89 @@ func Command() *cli.Command {
 	return &cli.Command{
 		Name:    "remove",
 		Aliases: []string{"rm"},
-		Usage:   "<src>",
+		Usage:   "<src> | --from-manifest <manifest>",
+		Flags: []cli.Flag{
+			objstrcli.GlobFlag(),
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: 100},
+			&cli.BoolFlag{Name: "verbose"},
+			&cli.StringFlag{Name: "from-manifest", Usage: "remove the objects listed in a manifest"},
+		},
 		Action: func(ctx *cli.Context) error {
-			var store = objstr.FromContextOrDefault(ctx.Context)
+			var (
+				store                 = objstr.FromContextOrDefault(ctx.Context)
+				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
+				verbose               = ctx.Bool("verbose")
+				fromManifest          = ctx.String("from-manifest")
+				arg                   = ctx.Args().Get(0)
+			)
+
+			if len(fromManifest) == 0 && !objstr.IsGlobURL(arg, ctx.Bool("glob")) {
+				src, err := objstr.ParseLiteralURL(arg)
 
-			src, err := url.Parse(ctx.Args().Get(0))
+				if err != nil {
+					return err
+				}
+
+				if err := store.Delete(ctx.Context, src); err != nil {
+					return err
+				}
+
+				return nil
+			}
+
+			objects, err := listObjects(ctx.Context, store, arg, fromManifest)
 
 			if err != nil {
 				return err
 			}
 
-			if err := store.Delete(context.Background(), src); err != nil {
-				return err
+			var mapper = iter.Mapper[*types.Object, error]{
+				MaxGoroutines: maxConcurrentRequests,
 			}
 
-			return nil
+			var errs = mapper.Map(objects, func(o **types.Object) error {
+				var err = store.Delete(ctx.Context, (*o).URL)
+
+				if err == nil && verbose {
+					fmt.Println(((*o).URL).String())
+				}
+
+				return err
+			})
+
+			return errors.Join(errs...)
 		},
 	}
 }
+
+func listObjects(ctx context.Context, store *objstr.ObjectStore, arg string, fromManifest string) ([]*types.Object, error) {
+	if len(fromManifest) == 0 {
+		return store.Glob(ctx, arg)
+	}
+
+	manifestURL, err := url.Parse(fromManifest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := manifest.Load(ctx, store, manifestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*types.Object
+
+	for _, e := range entries {
+		u, err := e.ParseURL()
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &types.Object{URL: u})
+	}
+
+	return res, nil
+}
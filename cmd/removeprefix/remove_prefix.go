@@ -1,7 +1,6 @@
 package removeprefix
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -34,7 +33,7 @@ func Command() *cli.Command {
 				return err
 			}
 
-			objects, err := store.ListPrefix(context.Background(), src)
+			objects, err := store.ListPrefix(ctx.Context, src)
 
 			if err != nil {
 				return err
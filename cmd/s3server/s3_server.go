@@ -0,0 +1,88 @@
+package s3server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/s3server"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name: "s3-server",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "listen", Value: "127.0.0.1:9000"},
+			&cli.StringSliceFlag{Name: "bucket", Usage: "<name>=<url>"},
+			&cli.StringSliceFlag{Name: "credentials", Usage: "<access key id>:<secret access key>", EnvVars: []string{"OBJSTR_S3_SERVER_CREDENTIALS"}},
+			&cli.BoolFlag{Name: "anonymous", Usage: "serve unsigned requests with full read and write access when no credentials are given"},
+			&cli.StringFlag{Name: "multipart-staging-url"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store = objstr.FromContextOrDefault(ctx.Context)
+				conf  = s3server.HandlerConfig{
+					Buckets:             make(map[string]string),
+					Credentials:         make(map[string]string),
+					Anonymous:           ctx.Bool("anonymous"),
+					MultipartStagingURL: ctx.String("multipart-staging-url"),
+				}
+			)
+
+			for _, b := range ctx.StringSlice("bucket") {
+				name, u, found := strings.Cut(b, "=")
+
+				if !found {
+					return fmt.Errorf("invalid bucket: %s", b)
+				}
+
+				conf.Buckets[name] = u
+			}
+
+			for _, c := range ctx.StringSlice("credentials") {
+				accessKeyId, secretAccessKey, found := strings.Cut(c, ":")
+
+				if !found {
+					return fmt.Errorf("invalid credentials for access key id: %s", accessKeyId)
+				}
+
+				conf.Credentials[accessKeyId] = secretAccessKey
+			}
+
+			handler, err := s3server.NewHandler(store, conf)
+
+			if err != nil {
+				return err
+			}
+
+			var server = &http.Server{
+				Addr:    ctx.String("listen"),
+				Handler: handler,
+			}
+
+			runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var shutdown = make(chan error, 1)
+
+			// in-flight requests are completed before returning
+			go func() {
+				<-runCtx.Done()
+				shutdown <- server.Shutdown(context.Background())
+			}()
+
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return <-shutdown
+		},
+	}
+}
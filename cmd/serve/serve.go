@@ -0,0 +1,81 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/gateway"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "[root]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "listen", Value: "127.0.0.1:8080"},
+			&cli.StringSliceFlag{Name: "mount", Usage: "<path>=<url>"},
+			&cli.StringSliceFlag{Name: "token", EnvVars: []string{"OBJSTR_SERVE_TOKENS"}},
+			&cli.StringSliceFlag{Name: "read-only", Usage: "<path prefix>"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store = objstr.FromContextOrDefault(ctx.Context)
+				conf  = gateway.HandlerConfig{
+					Mounts:           make(map[string]string),
+					BearerTokens:     ctx.StringSlice("token"),
+					ReadOnlyPrefixes: ctx.StringSlice("read-only"),
+				}
+			)
+
+			if ctx.Args().Len() > 0 {
+				conf.Mounts["/"] = ctx.Args().Get(0)
+			}
+
+			for _, m := range ctx.StringSlice("mount") {
+				p, u, found := strings.Cut(m, "=")
+
+				if !found {
+					return fmt.Errorf("invalid mount: %s", m)
+				}
+
+				conf.Mounts[p] = u
+			}
+
+			handler, err := gateway.NewHandler(store, conf)
+
+			if err != nil {
+				return err
+			}
+
+			var server = &http.Server{
+				Addr:    ctx.String("listen"),
+				Handler: handler,
+			}
+
+			runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var shutdown = make(chan error, 1)
+
+			// in-flight requests are completed before returning
+			go func() {
+				<-runCtx.Done()
+				shutdown <- server.Shutdown(context.Background())
+			}()
+
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return <-shutdown
+		},
+	}
+}
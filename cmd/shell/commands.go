@@ -0,0 +1,347 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/dustin/go-humanize"
+	"github.com/samber/lo"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, s *session, args []string) error
+}
+
+var commands = map[string]command{
+	"pwd":  {usage: "", run: pwd},
+	"cd":   {usage: "[dir]", run: cd},
+	"ls":   {usage: "[-l] [path]...", run: ls},
+	"cat":  {usage: "<path>...", run: cat},
+	"cp":   {usage: "<src>... <dst>", run: cp},
+	"mv":   {usage: "<src>... <dst>", run: mv},
+	"rm":   {usage: "<path>...", run: rm},
+	"stat": {usage: "<path>...", run: stat},
+	"put":  {usage: "<local file> [dst]", run: put},
+}
+
+func commandNames() []string {
+	var names = lo.Keys(commands)
+	slices.Sort(names)
+	return names
+}
+
+func pwd(ctx context.Context, s *session, args []string) error {
+	fmt.Fprintln(s.out, s.cwd.String())
+	return nil
+}
+
+func cd(ctx context.Context, s *session, args []string) error {
+	if len(args) == 0 {
+		s.cwd = s.home
+		return nil
+	}
+
+	u, err := s.resolve(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	s.cwd = dirURL(u)
+	return nil
+}
+
+func ls(ctx context.Context, s *session, args []string) error {
+	var long bool
+
+	if len(args) > 0 && args[0] == "-l" {
+		long, args = true, args[1:]
+	}
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	for _, arg := range args {
+		var (
+			objects []*types.Object
+			dir     string
+			err     error
+		)
+
+		if objstr.IsGlobURL(arg, false) {
+			objects, err = s.store.Glob(ctx, s.resolvePattern(arg))
+			dir = s.cwd.Path
+		} else {
+			var u *url.URL
+
+			if u, err = s.resolve(arg); err != nil {
+				return err
+			}
+
+			u = dirURL(u)
+			dir = u.Path
+			objects, err = s.store.ListPrefix(ctx, u, types.WithDelimiter("/"))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range objects {
+			var name = strings.TrimPrefix(obj.URL.Path, dir)
+
+			if obj.IsPrefix && !strings.HasSuffix(name, "/") {
+				name += "/"
+			}
+
+			if !long {
+				fmt.Fprintln(s.out, name)
+				continue
+			}
+
+			if obj.IsPrefix || obj.Metadata == nil {
+				fmt.Fprintf(s.out, "%10s  %-25s  %s\n", "-", "", name)
+				continue
+			}
+
+			fmt.Fprintf(
+				s.out,
+				"%10s  %-25s  %s\n",
+				humanize.IBytes(obj.Metadata.Size),
+				obj.Metadata.ModificationDate.Format("2006-01-02T15:04:05Z07:00"),
+				name,
+			)
+		}
+	}
+
+	return nil
+}
+
+func cat(ctx context.Context, s *session, args []string) error {
+	urls, err := s.expand(ctx, args)
+
+	if err != nil {
+		return err
+	}
+
+	for _, u := range urls {
+		r, err := s.store.Reader(ctx, u)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(s.out, r)
+		r.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cp(ctx context.Context, s *session, args []string) error {
+	return s.transfer(ctx, args, func(src *url.URL, dst *url.URL) error {
+		return s.store.Copy(ctx, src, dst)
+	})
+}
+
+func mv(ctx context.Context, s *session, args []string) error {
+	return s.transfer(ctx, args, func(src *url.URL, dst *url.URL) error {
+		return s.store.Move(ctx, src, dst)
+	})
+}
+
+func rm(ctx context.Context, s *session, args []string) error {
+	urls, err := s.expand(ctx, args)
+
+	if err != nil {
+		return err
+	}
+
+	for _, u := range urls {
+		if err := s.store.Delete(ctx, u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stat(ctx context.Context, s *session, args []string) error {
+	urls, err := s.expand(ctx, args)
+
+	if err != nil {
+		return err
+	}
+
+	for _, u := range urls {
+		md, err := s.store.ReadMetadata(ctx, u)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(s.out, objstrcli.NewStatRecord(u, md).Text())
+	}
+
+	return nil
+}
+
+func put(ctx context.Context, s *session, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("usage: put <local file> [dst]")
+	}
+
+	var dstArg = "."
+
+	if len(args) == 2 {
+		dstArg = args[1]
+	}
+
+	dst, err := s.resolve(dstArg)
+
+	if err != nil {
+		return err
+	}
+
+	if isDir(dstArg) {
+		dst = dst.JoinPath(path.Base(args[0]))
+	}
+
+	f, err := os.Open(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	var opts []types.WriteOption
+
+	if contentType := mime.TypeByExtension(path.Ext(dst.Path)); len(contentType) > 0 {
+		opts = append(opts, types.WithContentType(contentType))
+	}
+
+	w, err := s.store.Writer(ctx, dst, opts...)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, f); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
+
+// transfer applies fn to each source; the destination is treated as a
+// directory when it ends with "/" or when there are several sources. Objects
+// matched by a glob keep their path relative to the literal directory of the
+// pattern, and all destinations are resolved before fn is applied so that a
+// collision fails before anything is written.
+func (s *session) transfer(ctx context.Context, args []string, fn func(src *url.URL, dst *url.URL) error) error {
+	if len(args) < 2 {
+		return fmt.Errorf("source and destination must be specified")
+	}
+
+	var dstArg = args[len(args)-1]
+
+	dst, err := s.resolve(dstArg)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		toDir   = isDir(dstArg) || len(args) > 2 || objstr.IsGlobURL(args[0], false)
+		srcs    []*url.URL
+		targets []*url.URL
+		seen    = make(map[string]*url.URL)
+	)
+
+	for _, arg := range args[:len(args)-1] {
+		argSrcs, argTargets, err := s.transferTargets(ctx, arg, dst, toDir)
+
+		if err != nil {
+			return err
+		}
+
+		for i, target := range argTargets {
+			if prev, found := seen[target.String()]; found {
+				return fmt.Errorf("%s and %s would both be written to %s", prev, argSrcs[i], target)
+			}
+
+			seen[target.String()] = argSrcs[i]
+		}
+
+		srcs = append(srcs, argSrcs...)
+		targets = append(targets, argTargets...)
+	}
+
+	for i, src := range srcs {
+		if err := fn(src, targets[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *session) transferTargets(ctx context.Context, arg string, dst *url.URL, toDir bool) ([]*url.URL, []*url.URL, error) {
+	if !objstr.IsGlobURL(arg, false) {
+		src, err := s.resolve(arg)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if toDir {
+			return []*url.URL{src}, []*url.URL{dirURL(dst).JoinPath(path.Base(src.Path))}, nil
+		}
+
+		return []*url.URL{src}, []*url.URL{dst}, nil
+	}
+
+	var pattern = s.resolvePattern(arg)
+
+	objects, err := s.store.Glob(ctx, pattern)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, nil, fmt.Errorf("no match: %s", arg)
+	}
+
+	targets, err := s.store.GlobDestinations(pattern, objects, dirURL(dst))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var srcs = make([]*url.URL, 0, len(objects))
+
+	for _, obj := range objects {
+		srcs = append(srcs, obj.URL)
+	}
+
+	return srcs, targets, nil
+}
@@ -0,0 +1,145 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/samber/lo"
+)
+
+// complete extends the word under the cursor to the longest common prefix of
+// its candidates: command names for the first word, keys listed with a "/"
+// delimiter for the others.
+func (s *session) complete(ctx context.Context, line string, pos int) (string, int, bool) {
+	var (
+		start      = strings.LastIndexByte(line[:pos], ' ') + 1
+		word       = line[start:pos]
+		candidates []string
+	)
+
+	if len(strings.TrimSpace(line[:start])) == 0 {
+		candidates = lo.Filter(append(commandNames(), "help", "exit"), func(name string, _ int) bool {
+			return strings.HasPrefix(name, word)
+		})
+	} else {
+		candidates = s.completePath(ctx, word)
+	}
+
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	var completion = commonPrefix(candidates)
+
+	if len(candidates) == 1 && !strings.HasSuffix(completion, "/") {
+		completion += " "
+	}
+
+	if len(completion) <= len(word) {
+		return "", 0, false
+	}
+
+	return line[:start] + completion + line[pos:], start + len(completion), true
+}
+
+func (s *session) completePath(ctx context.Context, word string) []string {
+	var (
+		dirPart = word[:strings.LastIndexByte(word, '/')+1]
+		base    = word[len(dirPart):]
+	)
+
+	if strings.Contains(word, "://") && !strings.Contains(strings.SplitN(word, "://", 2)[1], "/") {
+		return nil
+	}
+
+	dir, err := s.resolve(lo.Ternary(len(dirPart) > 0, dirPart, "."))
+
+	if err != nil {
+		return nil
+	}
+
+	dir = dirURL(dir)
+
+	objects, err := s.store.ListPrefix(ctx, dir, types.WithDelimiter("/"))
+
+	if err != nil {
+		return nil
+	}
+
+	var res []string
+
+	for _, obj := range objects {
+		var name = strings.TrimPrefix(obj.URL.Path, dir.Path)
+
+		if obj.IsPrefix && !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+
+		if strings.HasPrefix(name, base) {
+			res = append(res, dirPart+name)
+		}
+	}
+
+	return res
+}
+
+func commonPrefix(values []string) string {
+	var res = values[0]
+
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, res) {
+			res = res[:len(res)-1]
+		}
+	}
+
+	return res
+}
+
+// splitArgs splits a command line on spaces, honoring single quotes, double
+// quotes and backslash escapes.
+func splitArgs(line string) ([]string, error) {
+	var (
+		res     []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t':
+			if inWord {
+				res = append(res, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	if inWord {
+		res = append(res, current.String())
+	}
+
+	return res, nil
+}
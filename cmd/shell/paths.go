@@ -0,0 +1,106 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+)
+
+// resolve interprets p as an absolute URL, a path absolute to the current
+// URL's host or a path relative to the current URL.
+func (s *session) resolve(p string) (*url.URL, error) {
+	if strings.Contains(p, "://") {
+		return objstr.ParseLiteralURL(p)
+	}
+
+	var u = *s.cwd
+	u.Path = s.resolvePath(objstr.UnescapeGlob(p))
+	return &u, nil
+}
+
+func (s *session) resolvePath(p string) string {
+	var res string
+
+	if strings.HasPrefix(p, "/") {
+		res = path.Clean(p)
+	} else {
+		res = path.Join(s.cwd.Path, p)
+	}
+
+	if isDir(p) && !strings.HasSuffix(res, "/") {
+		res += "/"
+	}
+
+	return res
+}
+
+// resolvePattern resolves a glob pattern without parsing it as an URL since
+// "?" is a glob meta character.
+func (s *session) resolvePattern(p string) string {
+	if strings.Contains(p, "://") {
+		return p
+	}
+
+	return s.cwd.Scheme + "://" + s.cwd.Host + s.resolvePath(p)
+}
+
+// expand resolves each argument, expanding glob patterns into the matching
+// objects.
+func (s *session) expand(ctx context.Context, args []string) ([]*url.URL, error) {
+	var res []*url.URL
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at least one path must be specified")
+	}
+
+	for _, arg := range args {
+		if !objstr.IsGlobURL(arg, false) {
+			u, err := s.resolve(arg)
+
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, u)
+			continue
+		}
+
+		objects, err := s.store.Glob(ctx, s.resolvePattern(arg))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(objects) == 0 {
+			return nil, fmt.Errorf("no match: %s", arg)
+		}
+
+		for _, obj := range objects {
+			res = append(res, obj.URL)
+		}
+	}
+
+	return res, nil
+}
+
+func isDir(p string) bool {
+	return strings.HasSuffix(p, "/") || p == "." || p == ".." || strings.HasSuffix(p, "/.") || strings.HasSuffix(p, "/..")
+}
+
+func dirURL(u *url.URL) *url.URL {
+	var res = *u
+
+	if !strings.HasPrefix(res.Path, "/") {
+		res.Path = "/" + res.Path
+	}
+
+	if !strings.HasSuffix(res.Path, "/") {
+		res.Path += "/"
+	}
+
+	return &res
+}
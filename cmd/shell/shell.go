@@ -0,0 +1,218 @@
+package shell
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "shell",
+		Usage: "[url]",
+		Action: func(ctx *cli.Context) error {
+			var store = objstr.FromContextOrDefault(ctx.Context)
+
+			home, err := homeURL(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			var s = session{store: store, home: home, cwd: home}
+
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return s.runScript(ctx.Context, os.Stdin)
+			}
+
+			return s.runInteractive(ctx.Context)
+		},
+	}
+}
+
+// session holds the state shared by the commands of a shell: all of them go
+// through the same ObjectStore, so backend connections and caches are reused
+// for the whole session.
+type session struct {
+	store *objstr.ObjectStore
+	home  *url.URL
+	cwd   *url.URL
+	out   io.Writer
+}
+
+func homeURL(arg string) (*url.URL, error) {
+	if len(arg) == 0 {
+		wd, err := os.Getwd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return dirURL(&url.URL{Scheme: "file", Path: filepath.ToSlash(wd)}), nil
+	}
+
+	u, err := url.Parse(arg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dirURL(u), nil
+}
+
+func (s *session) prompt() string {
+	return s.cwd.String() + "> "
+}
+
+func (s *session) runInteractive(ctx context.Context) error {
+	var fd = int(os.Stdin.Fd())
+
+	oldState, err := term.MakeRaw(fd)
+
+	if err != nil {
+		return err
+	}
+
+	defer term.Restore(fd, oldState)
+
+	var t = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, s.prompt())
+
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		t.SetSize(width, height)
+	}
+
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		return s.complete(ctx, line, pos)
+	}
+
+	s.out = t
+
+	for {
+		line, err := t.ReadLine()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		exit, err := s.execInterruptible(ctx, fd, oldState, line)
+
+		if err != nil {
+			fmt.Fprintf(t, "error: %s\n", err)
+		} else if exit {
+			return nil
+		}
+
+		t.SetPrompt(s.prompt())
+	}
+}
+
+// execInterruptible runs a command with the terminal back in cooked mode, so
+// that Ctrl-C raises SIGINT, which cancels the command's context instead of
+// killing the shell.
+func (s *session) execInterruptible(ctx context.Context, fd int, cooked *term.State, line string) (bool, error) {
+	if err := term.Restore(fd, cooked); err != nil {
+		return false, err
+	}
+
+	cmdCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	exit, err := s.exec(cmdCtx, line)
+	stop()
+
+	if _, rawErr := term.MakeRaw(fd); rawErr != nil {
+		return true, rawErr
+	}
+
+	return exit, err
+}
+
+// runScript executes commands read from a non terminal input, one per line,
+// and fails with the number of commands that failed.
+func (s *session) runScript(ctx context.Context, r io.Reader) error {
+	var (
+		scanner  = bufio.NewScanner(r)
+		failures int
+	)
+
+	s.out = os.Stdout
+
+	for scanner.Scan() {
+		exit, err := s.exec(ctx, scanner.Text())
+
+		if err != nil {
+			failures++
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
+
+		if exit {
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d commands failed", failures)
+	}
+
+	return nil
+}
+
+func (s *session) exec(ctx context.Context, line string) (bool, error) {
+	args, err := splitArgs(line)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true, nil
+	case "help":
+		s.help()
+		return false, nil
+	}
+
+	cmd, found := commands[args[0]]
+
+	if !found {
+		return false, fmt.Errorf("unknown command: %s", args[0])
+	}
+
+	return false, cmd.run(ctx, s, args[1:])
+}
+
+func (s *session) help() {
+	for _, name := range commandNames() {
+		fmt.Fprintln(s.out, strings.TrimSpace(fmt.Sprintf("%-6s %s", name, commands[name].usage)))
+	}
+
+	fmt.Fprintln(s.out, "help")
+	fmt.Fprintln(s.out, "exit")
+}
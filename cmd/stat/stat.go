@@ -0,0 +1,45 @@
+package stat
+
+import (
+	"net/url"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "stat",
+		Usage: "<url>...",
+		Action: func(ctx *cli.Context) error {
+			var store = objstr.FromContextOrDefault(ctx.Context)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			for _, arg := range ctx.Args().Slice() {
+				u, err := url.Parse(arg)
+
+				if err != nil {
+					return err
+				}
+
+				md, err := store.ReadMetadata(ctx.Context, u)
+
+				if err != nil {
+					return err
+				}
+
+				if err := printer.Print(objstrcli.NewStatRecord(u, md)); err != nil {
+					return err
+				}
+			}
+
+			return printer.Flush()
+		},
+	}
+}
@@ -1,15 +1,12 @@
 package sync
 
 import (
-	"context"
-	"errors"
-	"fmt"
+	"encoding/json"
 	"net/url"
+	"os"
 
 	"github.com/agnosticeng/objstr"
-	"github.com/agnosticeng/objstr/types"
-	"github.com/agnosticeng/objstr/utils"
-	"github.com/sourcegraph/conc/iter"
+	objstrcli "github.com/agnosticeng/objstr/cli"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,76 +15,94 @@ func Command() *cli.Command {
 		Name:  "sync",
 		Usage: "<left> <right>",
 		Flags: []cli.Flag{
-			&cli.IntFlag{Name: "max-concurrent-requests", Value: 100},
-			&cli.BoolFlag{Name: "verbose"},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.StringFlag{
+				Name:  "compare",
+				Value: string(objstr.CompareSize),
+				Usage: "comparison of objects present on both sides: size, size-mtime, etag or hash",
+			},
+			&cli.BoolFlag{Name: "dry-run", Usage: "print the operations without executing them"},
+			&cli.BoolFlag{Name: "delete", Usage: "delete objects of the right side missing from the left side"},
+			&cli.StringFlag{Name: "max-delete", Usage: "abort when more objects would be deleted, as a count (100) or a percentage (10%)"},
+			&cli.PathFlag{Name: "plan", Usage: "write the operations to a JSON plan file instead of executing them"},
 		},
 		Action: func(ctx *cli.Context) error {
 			var (
-				os                    = objstr.FromContextOrDefault(ctx.Context)
+				store                 = objstr.FromContextOrDefault(ctx.Context)
 				maxConcurrentRequests = ctx.Int("max-concurrent-requests")
-				opts                  []types.ListOption
+				dryRun                = ctx.Bool("dry-run")
+				planPath              = ctx.Path("plan")
 			)
 
-			srcPrefix, err := url.Parse(ctx.Args().Get(0))
+			compareMode, err := objstr.ParseCompareMode(ctx.String("compare"))
 
 			if err != nil {
 				return err
 			}
 
-			dstPrefix, err := url.Parse(ctx.Args().Get(1))
+			printer, err := objstrcli.NewPrinter(ctx)
 
 			if err != nil {
 				return err
 			}
 
-			srcObjs, err := os.ListPrefix(context.Background(), srcPrefix, opts...)
+			maxDelete, maxDeletePercent, err := objstrcli.ParseMaxDelete(ctx.String("max-delete"))
 
 			if err != nil {
 				return err
 			}
 
-			dstObjs, err := os.ListPrefix(context.Background(), dstPrefix, opts...)
+			srcPrefix, err := url.Parse(ctx.Args().Get(0))
 
 			if err != nil {
 				return err
 			}
 
-			var pairs = utils.Associate(
-				srcPrefix,
-				srcObjs,
-				dstPrefix,
-				dstObjs,
-			)
+			dstPrefix, err := url.Parse(ctx.Args().Get(1))
 
-			var mapper = iter.Mapper[utils.ObjectPair, error]{
-				MaxGoroutines: maxConcurrentRequests,
+			if err != nil {
+				return err
 			}
 
-			var errs = mapper.Map(pairs, func(pair *utils.ObjectPair) error {
-				switch {
-				case pair.Left == nil:
-					fmt.Println("DELETE", pair.Right.URL.String())
-					return os.Delete(ctx.Context, pair.Right.URL)
+			var opts = &objstr.SyncOptions{
+				MaxConcurrentRequests: maxConcurrentRequests,
+				CompareMode:           compareMode,
+				Delete:                ctx.Bool("delete"),
+				MaxDelete:             maxDelete,
+				MaxDeletePercent:      maxDeletePercent,
+				DryRun:                dryRun,
+				OnResult: func(res objstr.SyncResult) {
+					printer.Print(objstrcli.NewSyncRecord(res, dryRun))
+				},
+			}
 
-				case pair.Right == nil:
-					dstUrl, err := utils.GenerateDstURL(dstPrefix, srcPrefix, pair.Left.URL)
+			plan, err := store.PlanSync(ctx.Context, srcPrefix, dstPrefix, opts)
 
-					if err != nil {
-						return err
-					}
+			if err != nil {
+				return err
+			}
 
-					fmt.Println("COPY", pair.Left.URL.String(), dstUrl.String())
-					return os.Copy(ctx.Context, pair.Left.URL, dstUrl)
+			if len(planPath) > 0 {
+				return writePlan(planPath, plan)
+			}
 
-				case pair.Left.Metadata.Size != pair.Right.Metadata.Size:
-					return os.Copy(ctx.Context, pair.Left.URL, pair.Right.URL)
+			var applyErr = store.ApplySyncPlan(ctx.Context, plan, opts)
 
-				default:
-					return nil
-				}
-			})
+			if err := printer.Flush(); err != nil {
+				return err
+			}
 
-			return errors.Join(errs...)
+			return applyErr
 		},
 	}
 }
+
+func writePlan(path string, plan *objstr.SyncPlan) error {
+	content, err := json.MarshalIndent(plan, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
@@ -0,0 +1,189 @@
+package tail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderr "errors"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	initialChunkSize = 64 * 1024
+	maxChunkSize     = 16 * 1024 * 1024
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "tail",
+		Usage: "<src>",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "bytes", Aliases: []string{"c"}, Value: -1, Usage: "print the last N bytes"},
+			&cli.IntFlag{Name: "lines", Aliases: []string{"n"}, Value: 10, Usage: "print the last N lines"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store = objstr.FromContextOrDefault(ctx.Context)
+				n     = ctx.Int64("bytes")
+				lines = ctx.Int("lines")
+			)
+
+			src, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			md, err := store.ReadMetadata(ctx.Context, src)
+
+			if err != nil {
+				return err
+			}
+
+			var size = int64(md.Size)
+
+			if n >= 0 {
+				r, err := store.RangeReader(ctx.Context, src, max(size-n, 0), min(n, size))
+
+				if err != nil {
+					return err
+				}
+
+				defer r.Close()
+
+				_, err = io.Copy(os.Stdout, r)
+				return err
+			}
+
+			return tailLines(ctx.Context, store, src, size, lines)
+		},
+	}
+}
+
+// tailLines reads the object backwards with ReaderAt, doubling the chunk
+// size, until enough lines are buffered.
+func tailLines(ctx context.Context, store *objstr.ObjectStore, src *url.URL, size int64, lines int) error {
+	if lines <= 0 {
+		return nil
+	}
+
+	ra, err := store.ReaderAt(ctx, src)
+
+	if stderr.Is(err, stderr.ErrUnsupported) {
+		return streamTailLines(ctx, store, src, lines)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer ra.Close()
+
+	var (
+		start     = size
+		chunkSize = int64(initialChunkSize)
+		buf       []byte
+	)
+
+	for start > 0 {
+		var chunk = make([]byte, min(chunkSize, start))
+
+		start -= int64(len(chunk))
+
+		if _, err := ra.ReadAt(chunk, start); err != nil && !stderr.Is(err, io.EOF) {
+			return err
+		}
+
+		buf = append(chunk, buf...)
+
+		if _, found := lastLinesOffset(buf, lines); found {
+			break
+		}
+
+		chunkSize = min(chunkSize*2, maxChunkSize)
+	}
+
+	var offset, _ = lastLinesOffset(buf, lines)
+
+	_, err = os.Stdout.Write(buf[offset:])
+	return err
+}
+
+// lastLinesOffset returns the offset of the last n lines of buf, a trailing
+// newline does not start a new line.
+func lastLinesOffset(buf []byte, n int) (int, bool) {
+	var (
+		end   = len(buf)
+		count int
+	)
+
+	if end > 0 && buf[end-1] == '\n' {
+		end--
+	}
+
+	for {
+		var idx = bytes.LastIndexByte(buf[:end], '\n')
+
+		if idx < 0 {
+			return 0, false
+		}
+
+		count++
+
+		if count == n {
+			return idx + 1, true
+		}
+
+		end = idx
+	}
+}
+
+// streamTailLines keeps the last lines of a sequential read, for backends
+// without ReaderAt support.
+func streamTailLines(ctx context.Context, store *objstr.ObjectStore, src *url.URL, lines int) error {
+	r, err := store.Reader(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	var (
+		br   = bufio.NewReader(r)
+		ring [][]byte
+	)
+
+	for {
+		line, err := br.ReadBytes('\n')
+
+		if len(line) > 0 {
+			ring = append(ring, line)
+
+			if len(ring) > lines {
+				ring = ring[1:]
+			}
+		}
+
+		if stderr.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, line := range ring {
+		if _, err := os.Stdout.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/dustin/go-humanize"
+	"github.com/urfave/cli/v2"
+)
+
+type node struct {
+	Name     string  `json:"name"`
+	IsPrefix bool    `json:"is_prefix"`
+	Objects  uint64  `json:"objects"`
+	Size     uint64  `json:"size"`
+	Children []*node `json:"children,omitempty"`
+	index    map[string]*node
+}
+
+func (n *node) child(name string, isPrefix bool) *node {
+	if n.index == nil {
+		n.index = make(map[string]*node)
+	}
+
+	var key = name
+
+	if isPrefix {
+		key = name + "/"
+	}
+
+	c, found := n.index[key]
+
+	if !found {
+		c = &node{Name: key, IsPrefix: isPrefix}
+		n.index[key] = c
+		n.Children = append(n.Children, c)
+	}
+
+	return c
+}
+
+func (n *node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
+
+// prune drops the children below the given depth, 0 keeps everything.
+func (n *node) prune(depth int) {
+	if depth == 0 {
+		return
+	}
+
+	for _, c := range n.Children {
+		if depth == 1 {
+			c.Children = nil
+		} else {
+			c.prune(depth - 1)
+		}
+	}
+}
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "tree",
+		Usage: "<prefix>",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "depth", Usage: "maximum depth to display, 0 for no limit"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store  = objstr.FromContextOrDefault(ctx.Context)
+				depth  = ctx.Int("depth")
+				output = ctx.String("output")
+			)
+
+			if output != "text" && output != "json" {
+				return fmt.Errorf("tree only supports text and json output")
+			}
+
+			prefix, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			var root = &node{Name: prefix.String(), IsPrefix: true}
+
+			// object paths are made relative to the prefix in the form the
+			// backend lists them
+			if prefix, err = store.ResolvePrefix(prefix); err != nil {
+				return err
+			}
+
+			objects, err := store.ListPrefix(ctx.Context, prefix)
+
+			if err != nil {
+				return err
+			}
+
+			for _, obj := range objects {
+				var (
+					segments = strings.Split(strings.TrimPrefix(strings.TrimPrefix(obj.URL.Path, prefix.Path), "/"), "/")
+					current  = root
+				)
+
+				root.Objects++
+				root.Size += obj.Metadata.Size
+
+				for i, segment := range segments {
+					current = current.child(segment, i < len(segments)-1)
+					current.Objects++
+					current.Size += obj.Metadata.Size
+				}
+			}
+
+			root.sort()
+			root.prune(depth)
+
+			if output == "json" {
+				var enc = json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(root)
+			}
+
+			fmt.Println(label(root))
+			printChildren(root, "")
+			fmt.Println()
+			fmt.Println("size", humanize.Bytes(root.Size), "files", root.Objects)
+			return nil
+		},
+	}
+}
+
+func label(n *node) string {
+	if n.IsPrefix {
+		return fmt.Sprintf("%s (%d objects, %s)", n.Name, n.Objects, humanize.Bytes(n.Size))
+	}
+
+	return fmt.Sprintf("%s (%s)", n.Name, humanize.Bytes(n.Size))
+}
+
+func printChildren(n *node, indent string) {
+	for i, c := range n.Children {
+		var branch, nextIndent = "├── ", indent + "│   "
+
+		if i == len(n.Children)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+
+		fmt.Println(indent + branch + label(c))
+		printChildren(c, nextIndent)
+	}
+}
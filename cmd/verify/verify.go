@@ -0,0 +1,263 @@
+package verify
+
+import (
+	"context"
+	stderr "errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	objstrcli "github.com/agnosticeng/objstr/cli"
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/manifest"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/samber/lo"
+	"github.com/sourcegraph/conc/iter"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "verify",
+		Usage: "<left> <right> | --manifest <manifest> [prefix]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "manifest", Usage: "check the objects listed in a manifest, or their copies under prefix"},
+			&cli.StringFlag{Name: "hash", Value: "sha256", Usage: "hash used to compare objects: " + strings.Join(objstr.ChecksumAlgorithms(), ", ")},
+			&cli.IntFlag{Name: "max-concurrent-requests", Value: objstr.DefaultMaxConcurrentRequests},
+			&cli.BoolFlag{Name: "verbose", Usage: "also report objects that match"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store   = objstr.FromContextOrDefault(ctx.Context)
+				verbose = ctx.Bool("verbose")
+				records []*objstrcli.VerifyRecord
+			)
+
+			printer, err := objstrcli.NewPrinter(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			var onRecord = func(rec *objstrcli.VerifyRecord) {
+				if rec.Status != "ok" || verbose || !printer.IsText() {
+					printer.Print(rec)
+				}
+			}
+
+			if len(ctx.String("manifest")) > 0 {
+				records, err = verifyManifest(ctx, store, onRecord)
+			} else {
+				records, err = verifyPrefixes(ctx, store, onRecord)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := printer.Flush(); err != nil {
+				return err
+			}
+
+			var failures = lo.CountBy(records, func(rec *objstrcli.VerifyRecord) bool {
+				return rec.Status != "ok"
+			})
+
+			if failures > 0 {
+				return fmt.Errorf("%d of %d objects failed verification", failures, len(records))
+			}
+
+			return nil
+		},
+	}
+}
+
+func verifyManifest(ctx *cli.Context, store *objstr.ObjectStore, onRecord func(*objstrcli.VerifyRecord)) ([]*objstrcli.VerifyRecord, error) {
+	manifestURL, err := url.Parse(ctx.String("manifest"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix *url.URL
+
+	if ctx.Args().Len() > 0 {
+		if prefix, err = url.Parse(ctx.Args().Get(0)); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := manifest.Load(ctx.Context, store, manifestURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var mapper = iter.Mapper[*manifest.Entry, *objstrcli.VerifyRecord]{
+		MaxGoroutines: ctx.Int("max-concurrent-requests"),
+	}
+
+	return mapper.Map(entries, func(e **manifest.Entry) *objstrcli.VerifyRecord {
+		var rec = verifyEntry(ctx.Context, store, *e, prefix)
+		onRecord(rec)
+		return rec
+	}), nil
+}
+
+// verifyPrefixes streams every object found under either prefix through the
+// chosen hash and compares the digests of objects sharing the same path.
+func verifyPrefixes(ctx *cli.Context, store *objstr.ObjectStore, onRecord func(*objstrcli.VerifyRecord)) ([]*objstrcli.VerifyRecord, error) {
+	if ctx.Args().Len() != 2 {
+		return nil, fmt.Errorf("left and right prefixes must be specified")
+	}
+
+	var algorithm = ctx.String("hash")
+
+	if !slices.Contains(objstr.ChecksumAlgorithms(), algorithm) {
+		return nil, fmt.Errorf("unknown hash: %s", algorithm)
+	}
+
+	leftPrefix, err := url.Parse(ctx.Args().Get(0))
+
+	if err != nil {
+		return nil, err
+	}
+
+	rightPrefix, err := url.Parse(ctx.Args().Get(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	leftObjs, err := store.ListPrefix(ctx.Context, leftPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rightObjs, err := store.ListPrefix(ctx.Context, rightPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pairs  = types.Associate(leftPrefix, leftObjs, rightPrefix, rightObjs)
+		mapper = iter.Mapper[types.ObjectPair, *objstrcli.VerifyRecord]{
+			MaxGoroutines: ctx.Int("max-concurrent-requests"),
+		}
+	)
+
+	return mapper.Map(pairs, func(pair *types.ObjectPair) *objstrcli.VerifyRecord {
+		var rec = verifyPair(ctx.Context, store, pair, algorithm)
+		onRecord(rec)
+		return rec
+	}), nil
+}
+
+func verifyPair(ctx context.Context, store *objstr.ObjectStore, pair *types.ObjectPair, algorithm string) *objstrcli.VerifyRecord {
+	var rec = objstrcli.VerifyRecord{Path: pair.Path, Status: "ok"}
+
+	switch {
+	case pair.Left == nil:
+		rec.Right, rec.Status, rec.Detail = pair.Right.URL.String(), "missing", "missing on left"
+		return &rec
+	case pair.Right == nil:
+		rec.Left, rec.Status, rec.Detail = pair.Left.URL.String(), "missing", "missing on right"
+		return &rec
+	}
+
+	rec.Left, rec.Right = pair.Left.URL.String(), pair.Right.URL.String()
+
+	if pair.Left.Metadata.Size != pair.Right.Metadata.Size {
+		rec.Status, rec.Detail = "mismatch", fmt.Sprintf("size %d, expected %d", pair.Right.Metadata.Size, pair.Left.Metadata.Size)
+		return &rec
+	}
+
+	leftSum, err := store.Checksum(ctx, pair.Left.URL, algorithm)
+
+	if err != nil {
+		rec.Status, rec.Detail = "error", err.Error()
+		return &rec
+	}
+
+	rightSum, err := store.Checksum(ctx, pair.Right.URL, algorithm)
+
+	if err != nil {
+		rec.Status, rec.Detail = "error", err.Error()
+		return &rec
+	}
+
+	if leftSum != rightSum {
+		rec.Status, rec.Detail = "mismatch", fmt.Sprintf("%s %s, expected %s", algorithm, rightSum, leftSum)
+		return &rec
+	}
+
+	rec.Checksum = algorithm + ":" + leftSum
+	return &rec
+}
+
+// verifyEntry checks the object of a manifest entry, or its copy under
+// prefix. ETags are only compared on the original object since they depend
+// on the backend that stores it.
+func verifyEntry(ctx context.Context, store *objstr.ObjectStore, e *manifest.Entry, prefix *url.URL) *objstrcli.VerifyRecord {
+	var rec = objstrcli.VerifyRecord{Path: e.Key, Left: e.URL, Status: "ok"}
+
+	target, err := e.ParseURL()
+
+	if err != nil {
+		rec.Status, rec.Detail = "error", err.Error()
+		return &rec
+	}
+
+	if prefix != nil {
+		target = e.Destination(prefix, target)
+	}
+
+	if len(rec.Path) == 0 {
+		rec.Path = target.String()
+	}
+
+	rec.Right = target.String()
+
+	md, err := store.ReadMetadata(ctx, target)
+
+	switch {
+	case stderr.Is(err, errors.ErrObjectNotFound):
+		rec.Status = "missing"
+		return &rec
+	case err != nil:
+		rec.Status, rec.Detail = "error", err.Error()
+		return &rec
+	}
+
+	if md.Size != e.Size {
+		rec.Status, rec.Detail = "mismatch", fmt.Sprintf("size %d, expected %d", md.Size, e.Size)
+		return &rec
+	}
+
+	if prefix == nil && len(e.ETag) > 0 && len(md.ETag) > 0 && strings.Trim(md.ETag, "\"") != strings.Trim(e.ETag, "\"") {
+		rec.Status, rec.Detail = "mismatch", fmt.Sprintf("etag %s, expected %s", md.ETag, e.ETag)
+		return &rec
+	}
+
+	if algorithm, digest, found := e.SplitChecksum(); found {
+		sum, err := store.Checksum(ctx, target, algorithm)
+
+		if err != nil {
+			rec.Status, rec.Detail = "error", err.Error()
+			return &rec
+		}
+
+		if !strings.EqualFold(sum, digest) {
+			rec.Status, rec.Detail = "mismatch", fmt.Sprintf("%s %s, expected %s", algorithm, sum, digest)
+			return &rec
+		}
+
+		rec.Checksum = algorithm + ":" + sum
+	}
+
+	return &rec
+}
@@ -0,0 +1,57 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/davfs"
+	"github.com/urfave/cli/v2"
+	slogctx "github.com/veqryn/slog-context"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "webdav",
+		Usage: "<root>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "listen", Value: "127.0.0.1:8080"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var store = objstr.FromContextOrDefault(ctx.Context)
+
+			root, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			var server = &http.Server{
+				Addr:    ctx.String("listen"),
+				Handler: davfs.NewHandler(store, root, slogctx.FromCtx(ctx.Context)),
+			}
+
+			runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var shutdown = make(chan error, 1)
+
+			// in-flight requests are completed before returning
+			go func() {
+				<-runCtx.Done()
+				shutdown <- server.Shutdown(context.Background())
+			}()
+
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return <-shutdown
+		},
+	}
+}
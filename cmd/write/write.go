@@ -0,0 +1,104 @@
+package write
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:    "write",
+		Aliases: []string{"put"},
+		Usage:   "<dst>",
+		Flags: []cli.Flag{
+			&cli.PathFlag{Name: "file", Aliases: []string{"f"}, Usage: "read from a local file instead of stdin"},
+			&cli.StringFlag{Name: "content-type", Usage: "defaults to a guess from the destination extension"},
+			&cli.StringSliceFlag{Name: "metadata", Usage: "key=value, can be repeated"},
+			&cli.BoolFlag{Name: "if-absent", Usage: "fail when the destination already exists"},
+			&cli.DurationFlag{Name: "ttl", Usage: "expire the object after this duration, on backends that support it"},
+			&cli.BoolFlag{Name: "tee", Usage: "also copy the data to stdout"},
+		},
+		Action: func(ctx *cli.Context) error {
+			var (
+				store       = objstr.FromContextOrDefault(ctx.Context)
+				contentType = ctx.String("content-type")
+				md          = make(map[string]string)
+				opts        []types.WriteOption
+				r           io.Reader = os.Stdin
+			)
+
+			dst, err := url.Parse(ctx.Args().Get(0))
+
+			if err != nil {
+				return err
+			}
+
+			for _, kv := range ctx.StringSlice("metadata") {
+				k, v, found := strings.Cut(kv, "=")
+
+				if !found {
+					return fmt.Errorf("metadata must be key=value: %s", kv)
+				}
+
+				md[k] = v
+			}
+
+			if len(contentType) == 0 {
+				contentType = mime.TypeByExtension(path.Ext(dst.Path))
+			}
+
+			if len(contentType) > 0 {
+				opts = append(opts, types.WithContentType(contentType))
+			}
+
+			if len(md) > 0 {
+				opts = append(opts, types.WithMetadata(md))
+			}
+
+			if ctx.Bool("if-absent") {
+				opts = append(opts, types.WithIfAbsent())
+			}
+
+			if ttl := ctx.Duration("ttl"); ttl > 0 {
+				opts = append(opts, types.WithTTL(ttl))
+			}
+
+			if p := ctx.Path("file"); len(p) > 0 {
+				f, err := os.Open(p)
+
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+				r = f
+			}
+
+			if ctx.Bool("tee") {
+				r = io.TeeReader(r, os.Stdout)
+			}
+
+			w, err := store.Writer(ctx.Context, dst, opts...)
+
+			if err != nil {
+				return err
+			}
+
+			if _, err := io.Copy(w, r); err != nil {
+				w.Abort()
+				return err
+			}
+
+			return w.Close()
+		},
+	}
+}
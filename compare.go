@@ -0,0 +1,295 @@
+package objstr
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+)
+
+type CompareMode string
+
+const (
+	// CompareSize only compares sizes.
+	CompareSize CompareMode = "size"
+	// CompareSizeModTime also reports objects whose left side is newer than
+	// the right side; a newer right side is never reported.
+	CompareSizeModTime CompareMode = "size-mtime"
+	// CompareETag compares S3-style ETags, multipart ETags included, and
+	// falls back to CompareHash when one side has no such ETag.
+	CompareETag CompareMode = "etag"
+	// CompareHash reads both objects and compares their SHA-256.
+	CompareHash CompareMode = "hash"
+)
+
+var CompareModes = []CompareMode{CompareSize, CompareSizeModTime, CompareETag, CompareHash}
+
+func ParseCompareMode(s string) (CompareMode, error) {
+	if len(s) == 0 {
+		return CompareSize, nil
+	}
+
+	if !slices.Contains(CompareModes, CompareMode(s)) {
+		return "", fmt.Errorf("unknown compare mode: %s", s)
+	}
+
+	return CompareMode(s), nil
+}
+
+// compare reports whether two objects found at the same relative path
+// differ according to the mode.
+func (os *ObjectStore) compare(ctx context.Context, mode CompareMode, left *types.Object, right *types.Object) (DiffKind, bool, error) {
+	if left.Metadata.Size != right.Metadata.Size {
+		return DiffSizeDiffers, true, nil
+	}
+
+	switch mode {
+	case CompareSize, "":
+		return 0, false, nil
+
+	case CompareSizeModTime:
+		return DiffModTimeDiffers, left.Metadata.ModificationDate.After(right.Metadata.ModificationDate), nil
+
+	case CompareETag:
+		equal, err := os.etagsEqual(ctx, left, right)
+		return DiffETagDiffers, !equal, err
+
+	case CompareHash:
+		equal, err := os.hashesEqual(ctx, left, right)
+		return DiffContentDiffers, !equal, err
+
+	default:
+		return 0, false, fmt.Errorf("unknown compare mode: %s", mode)
+	}
+}
+
+// verify checks a copy destination against its source.
+func (os *ObjectStore) verify(ctx context.Context, src *url.URL, dst *url.URL, mode CompareMode) error {
+	srcMd, err := os.ReadMetadata(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	dstMd, err := os.ReadMetadata(ctx, dst)
+
+	if err != nil {
+		return err
+	}
+
+	kind, differs, err := os.compare(
+		ctx,
+		mode,
+		&types.Object{URL: src, Metadata: srcMd},
+		&types.Object{URL: dst, Metadata: dstMd},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if differs {
+		return fmt.Errorf("%w: %s: %s", errors.ErrVerificationFailed, kind, dst.String())
+	}
+
+	return nil
+}
+
+func (os *ObjectStore) hashesEqual(ctx context.Context, left *types.Object, right *types.Object) (bool, error) {
+	leftSum, err := os.hashObject(ctx, left, sha256.New())
+
+	if err != nil {
+		return false, err
+	}
+
+	rightSum, err := os.hashObject(ctx, right, sha256.New())
+
+	if err != nil {
+		return false, err
+	}
+
+	return leftSum == rightSum, nil
+}
+
+func (os *ObjectStore) hashObject(ctx context.Context, obj *types.Object, w io.Writer) (string, error) {
+	r, err := os.Reader(ctx, obj.URL)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer r.Close()
+
+	if _, err := io.Copy(w, r); err != nil {
+		return "", err
+	}
+
+	if h, ok := w.(hash.Hash); ok {
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	return "", nil
+}
+
+var s3ETagRegexp = regexp.MustCompile(`^([0-9a-f]{32})(?:-([0-9]+))?$`)
+
+// parseS3ETag returns the number of parts of an S3 ETag, 0 for a plain MD5.
+func parseS3ETag(etag string) (int, bool) {
+	var m = s3ETagRegexp.FindStringSubmatch(etag)
+
+	if m == nil {
+		return 0, false
+	}
+
+	if len(m[2]) == 0 {
+		return 0, true
+	}
+
+	parts, err := strconv.Atoi(m[2])
+	return parts, err == nil && parts > 0
+}
+
+// etagsEqual compares S3-style ETags. A multipart ETag is the MD5 of the
+// concatenated part MD5s followed by the part count, so it can only be
+// compared to another ETag after re-hashing the other object with the part
+// size that produced it; candidate part sizes are derived from the part
+// count and the usual client defaults.
+func (os *ObjectStore) etagsEqual(ctx context.Context, left *types.Object, right *types.Object) (bool, error) {
+	var (
+		leftETag            = strings.ToLower(strings.Trim(left.Metadata.ETag, "\""))
+		rightETag           = strings.ToLower(strings.Trim(right.Metadata.ETag, "\""))
+		leftParts, leftOk   = parseS3ETag(leftETag)
+		rightParts, rightOk = parseS3ETag(rightETag)
+	)
+
+	if !leftOk || !rightOk {
+		return os.hashesEqual(ctx, left, right)
+	}
+
+	if leftParts == rightParts {
+		return leftETag == rightETag, nil
+	}
+
+	var (
+		target = leftETag
+		parts  = leftParts
+		other  = right
+	)
+
+	if leftParts == 0 {
+		target, parts, other = rightETag, rightParts, left
+	}
+
+	var hashers []*partHasher
+
+	for _, partSize := range candidatePartSizes(other.Metadata.Size, parts) {
+		hashers = append(hashers, &partHasher{size: partSize, cur: md5.New()})
+	}
+
+	if len(hashers) == 0 {
+		return os.hashesEqual(ctx, left, right)
+	}
+
+	var writers []io.Writer
+
+	for _, h := range hashers {
+		writers = append(writers, h)
+	}
+
+	if _, err := os.hashObject(ctx, other, io.MultiWriter(writers...)); err != nil {
+		return false, err
+	}
+
+	for _, h := range hashers {
+		if h.etag() == target {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+var commonPartSizesMiB = []int64{5, 8, 10, 15, 16, 25, 32, 50, 64, 100, 128, 256, 512, 1024}
+
+func candidatePartSizes(size uint64, parts int) []int64 {
+	const mib = 1024 * 1024
+
+	var (
+		res        []int64
+		candidates []int64
+		exact      = (int64(size) + int64(parts) - 1) / int64(parts)
+	)
+
+	for _, s := range commonPartSizesMiB {
+		candidates = append(candidates, s*mib)
+	}
+
+	candidates = append(candidates, exact, (exact+mib-1)/mib*mib)
+
+	for _, partSize := range candidates {
+		if partSize <= 0 || slices.Contains(res, partSize) {
+			continue
+		}
+
+		if (int64(size)+partSize-1)/partSize == int64(parts) {
+			res = append(res, partSize)
+		}
+	}
+
+	return res
+}
+
+type partHasher struct {
+	size int64
+	n    int64
+	cur  hash.Hash
+	sums []byte
+	num  int
+}
+
+func (ph *partHasher) Write(p []byte) (int, error) {
+	var total = len(p)
+
+	for len(p) > 0 {
+		var k = min(int64(len(p)), ph.size-ph.n)
+
+		ph.cur.Write(p[:k])
+		ph.n += k
+		p = p[k:]
+
+		if ph.n == ph.size {
+			ph.flush()
+		}
+	}
+
+	return total, nil
+}
+
+func (ph *partHasher) flush() {
+	if ph.n == 0 {
+		return
+	}
+
+	ph.sums = ph.cur.Sum(ph.sums)
+	ph.num++
+	ph.n = 0
+	ph.cur.Reset()
+}
+
+func (ph *partHasher) etag() string {
+	ph.flush()
+
+	var sum = md5.Sum(ph.sums)
+	return hex.EncodeToString(sum[:]) + "-" + strconv.Itoa(ph.num)
+}
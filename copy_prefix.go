@@ -0,0 +1,72 @@
+package objstr
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+)
+
+const DefaultMaxConcurrentRequests = 100
+
+type CopyPrefixOptions struct {
+	// MaxConcurrentRequests bounds the number of objects copied at once,
+	// DefaultMaxConcurrentRequests is used when zero.
+	MaxConcurrentRequests int
+	CopyOptions           []types.CopyOption
+	// OnResult is called once per object, possibly concurrently.
+	OnResult func(CopyResult)
+}
+
+type CopyResult struct {
+	Src *url.URL
+	Dst *url.URL
+	Err error
+}
+
+func maxConcurrentRequests(n int) int {
+	if n <= 0 {
+		return DefaultMaxConcurrentRequests
+	}
+
+	return n
+}
+
+// CopyPrefix copies every object under srcPrefix to the same relative path
+// under dstPrefix. Per-object failures don't stop the other copies, they are
+// reported to OnResult and joined in the returned error.
+func (os *ObjectStore) CopyPrefix(ctx context.Context, srcPrefix *url.URL, dstPrefix *url.URL, opts *CopyPrefixOptions) error {
+	if opts == nil {
+		opts = &CopyPrefixOptions{}
+	}
+
+	objects, err := os.ListPrefix(ctx, srcPrefix)
+
+	if err != nil {
+		return err
+	}
+
+	var mapper = iter.Mapper[*types.Object, error]{
+		MaxGoroutines: maxConcurrentRequests(opts.MaxConcurrentRequests),
+	}
+
+	var errs = mapper.Map(objects, func(o **types.Object) error {
+		var res = CopyResult{Src: (*o).URL}
+
+		res.Dst, res.Err = types.GenerateDstURL(dstPrefix, srcPrefix, res.Src)
+
+		if res.Err == nil {
+			res.Err = os.Copy(ctx, res.Src, res.Dst, opts.CopyOptions...)
+		}
+
+		if opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+
+		return res.Err
+	})
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,460 @@
+package davfs
+
+import (
+	"context"
+	stderr "errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/net/webdav"
+)
+
+// FileSystem implements webdav.FileSystem on top of an ObjectStore prefix.
+// Collections are derived from delimiter listings; since object stores have
+// no empty directories, collections created with MKCOL only live in memory
+// until an object is written below them.
+type FileSystem struct {
+	store       *objstr.ObjectStore
+	root        *url.URL
+	dirsLock    sync.Mutex
+	virtualDirs map[string]bool
+}
+
+var _ webdav.FileSystem = (*FileSystem)(nil)
+
+func NewFileSystem(store *objstr.ObjectStore, root *url.URL) *FileSystem {
+	return &FileSystem{
+		store:       store,
+		root:        root,
+		virtualDirs: make(map[string]bool),
+	}
+}
+
+func cleanName(name string) string {
+	return path.Join("/", name)
+}
+
+func fsName(name string) string {
+	var res = strings.TrimPrefix(cleanName(name), "/")
+
+	if len(res) == 0 {
+		return "."
+	}
+
+	return res
+}
+
+func (dfs *FileSystem) url(name string) *url.URL {
+	var u = *dfs.root
+	u.Path = path.Join("/", u.Path, name)
+	return &u
+}
+
+func (dfs *FileSystem) prefixURL(name string) *url.URL {
+	var u = dfs.url(name)
+
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path = u.Path + "/"
+	}
+
+	return u
+}
+
+func (dfs *FileSystem) isVirtualDir(name string) bool {
+	dfs.dirsLock.Lock()
+	defer dfs.dirsLock.Unlock()
+
+	return dfs.virtualDirs[cleanName(name)]
+}
+
+func (dfs *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	info, err := objstr.FS(ctx, dfs.store, dfs.root).Stat(fsName(name))
+
+	if stderr.Is(err, fs.ErrNotExist) && dfs.isVirtualDir(name) {
+		return &dirInfo{name: path.Base(cleanName(name))}, nil
+	}
+
+	return info, err
+}
+
+func (dfs *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if _, err := dfs.Stat(ctx, name); err == nil {
+		return os.ErrExist
+	}
+
+	parent, err := dfs.Stat(ctx, path.Dir(cleanName(name)))
+
+	if err != nil {
+		return err
+	}
+
+	if !parent.IsDir() {
+		return os.ErrInvalid
+	}
+
+	dfs.dirsLock.Lock()
+	defer dfs.dirsLock.Unlock()
+
+	dfs.virtualDirs[cleanName(name)] = true
+	return nil
+}
+
+func (dfs *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return dfs.openWriter(ctx, name)
+	}
+
+	var ofs = objstr.FS(ctx, dfs.store, dfs.root)
+
+	f, err := ofs.Open(fsName(name))
+
+	if stderr.Is(err, fs.ErrNotExist) && dfs.isVirtualDir(name) {
+		return &dirFile{info: &dirInfo{name: path.Base(cleanName(name))}}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if info.IsDir() {
+		f.Close()
+
+		entries, err := ofs.ReadDir(fsName(name))
+
+		if err != nil && !stderr.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		var d = &dirFile{info: info}
+
+		for _, entry := range entries {
+			if entryInfo, err := entry.Info(); err == nil {
+				d.entries = append(d.entries, entryInfo)
+			}
+		}
+
+		return d, nil
+	}
+
+	return &readFile{f: f, info: info}, nil
+}
+
+func (dfs *FileSystem) openWriter(ctx context.Context, name string) (webdav.File, error) {
+	if cleanName(name) == "/" {
+		return nil, os.ErrInvalid
+	}
+
+	parent, err := dfs.Stat(ctx, path.Dir(cleanName(name)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !parent.IsDir() {
+		return nil, os.ErrInvalid
+	}
+
+	w, err := dfs.store.Writer(ctx, dfs.url(name))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &writeFile{
+		ctx:  ctx,
+		w:    w,
+		name: path.Base(cleanName(name)),
+	}, nil
+}
+
+func (dfs *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	if cleanName(name) == "/" {
+		return os.ErrInvalid
+	}
+
+	info, err := dfs.Stat(ctx, name)
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return dfs.store.Delete(ctx, dfs.url(name))
+	}
+
+	var res *multierror.Error
+
+	objects, err := dfs.store.ListPrefix(ctx, dfs.prefixURL(name))
+
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if err := dfs.store.Delete(ctx, obj.URL); err != nil && !isNotFound(err) {
+			res = multierror.Append(res, err)
+		}
+	}
+
+	dfs.dirsLock.Lock()
+	defer dfs.dirsLock.Unlock()
+
+	for dir := range dfs.virtualDirs {
+		if dir == cleanName(name) || strings.HasPrefix(dir, cleanName(name)+"/") {
+			delete(dfs.virtualDirs, dir)
+		}
+	}
+
+	return res.ErrorOrNil()
+}
+
+func (dfs *FileSystem) Rename(ctx context.Context, oldName string, newName string) error {
+	info, err := dfs.Stat(ctx, oldName)
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return dfs.store.Move(ctx, dfs.url(oldName), dfs.url(newName))
+	}
+
+	var srcPrefix = dfs.prefixURL(oldName)
+
+	objects, err := dfs.store.ListPrefix(ctx, srcPrefix)
+
+	if err != nil {
+		return err
+	}
+
+	var dstPrefix = dfs.prefixURL(newName)
+
+	for _, obj := range objects {
+		var dst = *dstPrefix
+		dst.Path = dstPrefix.Path + strings.TrimPrefix(obj.URL.Path, srcPrefix.Path)
+
+		// objects are moved one by one, a failure leaves the remaining
+		// sources untouched
+		if err := dfs.store.Move(ctx, obj.URL, &dst); err != nil {
+			return err
+		}
+	}
+
+	dfs.dirsLock.Lock()
+	defer dfs.dirsLock.Unlock()
+
+	for dir := range dfs.virtualDirs {
+		if dir == cleanName(oldName) || strings.HasPrefix(dir, cleanName(oldName)+"/") {
+			delete(dfs.virtualDirs, dir)
+			dfs.virtualDirs[cleanName(newName)+strings.TrimPrefix(dir, cleanName(oldName))] = true
+		}
+	}
+
+	return nil
+}
+
+func isNotFound(err error) bool {
+	return stderr.Is(err, errors.ErrObjectNotFound) || stderr.Is(err, fs.ErrNotExist)
+}
+
+type dirInfo struct {
+	name string
+}
+
+func (di *dirInfo) Name() string {
+	return di.name
+}
+
+func (di *dirInfo) Size() int64 {
+	return 0
+}
+
+func (di *dirInfo) Mode() fs.FileMode {
+	return fs.ModeDir | 0755
+}
+
+func (di *dirInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (di *dirInfo) IsDir() bool {
+	return true
+}
+
+func (di *dirInfo) Sys() any {
+	return nil
+}
+
+type dirFile struct {
+	info    fs.FileInfo
+	entries []fs.FileInfo
+	off     int
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (d *dirFile) Write([]byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (d *dirFile) Seek(int64, int) (int64, error) {
+	return 0, os.ErrInvalid
+}
+
+func (d *dirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	var remaining = d.entries[d.off:]
+
+	if count <= 0 {
+		d.off = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	var res = remaining[:min(count, len(remaining))]
+	d.off += len(res)
+	return res, nil
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+type readFile struct {
+	f    fs.File
+	info fs.FileInfo
+}
+
+func (rf *readFile) Close() error {
+	return rf.f.Close()
+}
+
+func (rf *readFile) Read(p []byte) (int, error) {
+	return rf.f.Read(p)
+}
+
+func (rf *readFile) Write([]byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (rf *readFile) Seek(offset int64, whence int) (int64, error) {
+	return rf.f.(io.Seeker).Seek(offset, whence)
+}
+
+func (rf *readFile) Readdir(int) ([]fs.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (rf *readFile) Stat() (fs.FileInfo, error) {
+	return rf.info, nil
+}
+
+func (rf *readFile) ETag(ctx context.Context) (string, error) {
+	if md, ok := rf.info.Sys().(*types.ObjectMetadata); ok && len(md.ETag) > 0 {
+		if strings.HasPrefix(md.ETag, "\"") {
+			return md.ETag, nil
+		}
+
+		return "\"" + md.ETag + "\"", nil
+	}
+
+	return "", webdav.ErrNotImplemented
+}
+
+type writeFile struct {
+	ctx      context.Context
+	w        types.Writer
+	name     string
+	size     int64
+	writeErr error
+}
+
+func (wf *writeFile) Close() error {
+	if wf.writeErr != nil || requestBodyFailed(wf.ctx) {
+		return wf.w.Abort()
+	}
+
+	return wf.w.Close()
+}
+
+func (wf *writeFile) Read([]byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (wf *writeFile) Write(p []byte) (int, error) {
+	n, err := wf.w.Write(p)
+	wf.size += int64(n)
+
+	if err != nil {
+		wf.writeErr = err
+	}
+
+	return n, err
+}
+
+func (wf *writeFile) Seek(int64, int) (int64, error) {
+	return 0, os.ErrInvalid
+}
+
+func (wf *writeFile) Readdir(int) ([]fs.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (wf *writeFile) Stat() (fs.FileInfo, error) {
+	return &writeFileInfo{name: wf.name, size: wf.size, modTime: time.Now()}, nil
+}
+
+type writeFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (wi *writeFileInfo) Name() string {
+	return wi.name
+}
+
+func (wi *writeFileInfo) Size() int64 {
+	return wi.size
+}
+
+func (wi *writeFileInfo) Mode() fs.FileMode {
+	return 0644
+}
+
+func (wi *writeFileInfo) ModTime() time.Time {
+	return wi.modTime
+}
+
+func (wi *writeFileInfo) IsDir() bool {
+	return false
+}
+
+func (wi *writeFileInfo) Sys() any {
+	return nil
+}
@@ -0,0 +1,58 @@
+package davfs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/agnosticeng/objstr"
+	"golang.org/x/net/webdav"
+)
+
+type bodyContextKey struct{}
+
+// trackingBody records read errors of the request body so that a PUT whose
+// body was cut short aborts the destination writer instead of committing it
+type trackingBody struct {
+	io.ReadCloser
+	err error
+}
+
+func (tb *trackingBody) Read(p []byte) (int, error) {
+	n, err := tb.ReadCloser.Read(p)
+
+	if err != nil && err != io.EOF {
+		tb.err = err
+	}
+
+	return n, err
+}
+
+func requestBodyFailed(ctx context.Context) bool {
+	tb, ok := ctx.Value(bodyContextKey{}).(*trackingBody)
+	return ok && tb.err != nil
+}
+
+func NewHandler(store *objstr.ObjectStore, root *url.URL, logger *slog.Logger) http.Handler {
+	var handler = &webdav.Handler{
+		FileSystem: NewFileSystem(store, root),
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				logger.Info(err.Error(), "method", r.Method, "path", r.URL.Path)
+			}
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			var tb = &trackingBody{ReadCloser: r.Body}
+			r = r.WithContext(context.WithValue(r.Context(), bodyContextKey{}, tb))
+			r.Body = tb
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
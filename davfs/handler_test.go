@@ -0,0 +1,112 @@
+package davfs_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/davfs"
+)
+
+func TestHandler(t *testing.T) {
+	var ctx = context.Background()
+
+	store, err := objstr.NewObjectStore(ctx, objstr.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer store.Close()
+
+	root, err := url.Parse("memory://davfs/root/")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(davfs.NewHandler(store, root, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer server.Close()
+
+	var do = func(method string, p string, body string, headers map[string]string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, server.URL+p, strings.NewReader(body))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := server.Client().Do(req)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer resp.Body.Close()
+
+		content, err := io.ReadAll(resp.Body)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp.StatusCode, string(content)
+	}
+
+	for _, dir := range []string{"/dir/", "/moved/"} {
+		if status, _ := do("MKCOL", dir, "", nil); status != http.StatusCreated {
+			t.Fatalf("MKCOL: unexpected status %d", status)
+		}
+	}
+
+	if status, _ := do(http.MethodPut, "/dir/a.txt", "hello", nil); status != http.StatusCreated {
+		t.Fatalf("PUT: unexpected status %d", status)
+	}
+
+	if status, content := do(http.MethodGet, "/dir/a.txt", "", nil); status != http.StatusOK || content != "hello" {
+		t.Fatalf("GET: unexpected response %d %q", status, content)
+	}
+
+	status, content := do("PROPFIND", "/dir/", "", map[string]string{"Depth": "1"})
+
+	if status != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND: unexpected status %d", status)
+	}
+
+	if !strings.Contains(content, "/dir/a.txt") || !strings.Contains(content, "<D:getcontentlength>5</D:getcontentlength>") {
+		t.Fatalf("PROPFIND: a.txt missing from response: %s", content)
+	}
+
+	if status, _ := do("MOVE", "/dir/a.txt", "", map[string]string{"Destination": server.URL + "/moved/b.txt"}); status != http.StatusCreated {
+		t.Fatalf("MOVE: unexpected status %d", status)
+	}
+
+	if status, _ := do(http.MethodGet, "/dir/a.txt", "", nil); status != http.StatusNotFound {
+		t.Fatalf("GET after MOVE: unexpected status %d for the source", status)
+	}
+
+	if status, content := do(http.MethodGet, "/moved/b.txt", "", nil); status != http.StatusOK || content != "hello" {
+		t.Fatalf("GET after MOVE: unexpected response %d %q", status, content)
+	}
+
+	md, err := store.ReadMetadata(ctx, root.JoinPath("moved", "b.txt"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if md.Size != 5 {
+		t.Fatalf("unexpected size of the moved object: %d", md.Size)
+	}
+}
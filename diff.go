@@ -0,0 +1,149 @@
+package objstr
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+)
+
+type DiffKind int
+
+const (
+	DiffRightMissing DiffKind = iota
+	DiffLeftMissing
+	DiffSizeDiffers
+	DiffModTimeDiffers
+	DiffETagDiffers
+	DiffContentDiffers
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffRightMissing:
+		return "RIGHT MISSING"
+	case DiffLeftMissing:
+		return "LEFT MISSING"
+	case DiffSizeDiffers:
+		return "SIZE DIFFERS"
+	case DiffModTimeDiffers:
+		return "MTIME DIFFERS"
+	case DiffETagDiffers:
+		return "ETAG DIFFERS"
+	case DiffContentDiffers:
+		return "CONTENT DIFFERS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type DiffOptions struct {
+	// CompareMode defaults to CompareSize.
+	CompareMode CompareMode
+	// MaxConcurrentRequests bounds the number of objects compared at once
+	// when the mode needs to read them, DefaultMaxConcurrentRequests is used
+	// when zero.
+	MaxConcurrentRequests int
+	// OnDifference is called for every difference, in listing order.
+	OnDifference func(Difference)
+}
+
+type Difference struct {
+	Kind DiffKind
+	// Path is relative to the compared prefixes.
+	Path  string
+	Left  *types.Object
+	Right *types.Object
+}
+
+type comparison struct {
+	kind    DiffKind
+	differs bool
+	err     error
+}
+
+type DiffResult struct {
+	Differences []Difference
+	LeftFiles   uint64
+	RightFiles  uint64
+	LeftSize    uint64
+	RightSize   uint64
+}
+
+// Diff compares the objects under two prefixes by relative path, using the
+// configured compare mode for objects present on both sides.
+func (os *ObjectStore) Diff(ctx context.Context, leftPrefix *url.URL, rightPrefix *url.URL, opts *DiffOptions) (*DiffResult, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+
+	leftObjs, err := os.ListPrefix(ctx, leftPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rightObjs, err := os.ListPrefix(ctx, rightPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res   DiffResult
+		pairs = types.Associate(
+			leftPrefix,
+			leftObjs,
+			rightPrefix,
+			rightObjs,
+		)
+		mapper = iter.Mapper[types.ObjectPair, comparison]{
+			MaxGoroutines: maxConcurrentRequests(opts.MaxConcurrentRequests),
+		}
+	)
+
+	var comparisons = mapper.Map(pairs, func(pair *types.ObjectPair) comparison {
+		if pair.Left == nil || pair.Right == nil {
+			return comparison{}
+		}
+
+		kind, differs, err := os.compare(ctx, opts.CompareMode, pair.Left, pair.Right)
+		return comparison{kind: kind, differs: differs, err: err}
+	})
+
+	for i, pair := range pairs {
+		if pair.Left != nil {
+			res.LeftFiles++
+			res.LeftSize += pair.Left.Metadata.Size
+		}
+
+		if pair.Right != nil {
+			res.RightFiles++
+			res.RightSize += pair.Right.Metadata.Size
+		}
+
+		var diff = Difference{Path: pair.Path, Left: pair.Left, Right: pair.Right}
+
+		switch {
+		case pair.Right == nil:
+			diff.Kind = DiffRightMissing
+		case pair.Left == nil:
+			diff.Kind = DiffLeftMissing
+		case comparisons[i].err != nil:
+			return nil, comparisons[i].err
+		case comparisons[i].differs:
+			diff.Kind = comparisons[i].kind
+		default:
+			continue
+		}
+
+		res.Differences = append(res.Differences, diff)
+
+		if opts.OnDifference != nil {
+			opts.OnDifference(diff)
+		}
+	}
+
+	return &res, nil
+}
@@ -3,5 +3,7 @@ package errors
 import "errors"
 
 var (
-	ErrObjectNotFound = errors.New("object not found")
+	ErrObjectNotFound      = errors.New("object not found")
+	ErrObjectAlreadyExists = errors.New("object already exists")
+	ErrVerificationFailed  = errors.New("verification failed")
 )
@@ -0,0 +1,472 @@
+package objstr
+
+import (
+	"bufio"
+	"context"
+	stderr "errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+)
+
+// ObjectFS exposes the objects stored under a prefix as an io/fs file system.
+// Directories are derived from "/" delimited listings.
+type ObjectFS struct {
+	ctx    context.Context
+	store  *ObjectStore
+	prefix *url.URL
+}
+
+var (
+	_ fs.FS         = (*ObjectFS)(nil)
+	_ fs.StatFS     = (*ObjectFS)(nil)
+	_ fs.ReadDirFS  = (*ObjectFS)(nil)
+	_ fs.ReadFileFS = (*ObjectFS)(nil)
+	_ fs.SubFS      = (*ObjectFS)(nil)
+)
+
+func FS(ctx context.Context, store *ObjectStore, prefix *url.URL) *ObjectFS {
+	return &ObjectFS{
+		ctx:    ctx,
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+func (ofs *ObjectFS) url(name string) *url.URL {
+	var u = *ofs.prefix
+	u.Path = path.Join("/", u.Path, name)
+	return &u
+}
+
+func (ofs *ObjectFS) dirURL(name string) *url.URL {
+	var u = ofs.url(name)
+
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path = u.Path + "/"
+	}
+
+	return u
+}
+
+func (ofs *ObjectFS) list(name string) ([]*types.Object, error) {
+	return ofs.store.ListPrefix(ofs.ctx, ofs.dirURL(name), types.WithDelimiter("/"))
+}
+
+func (ofs *ObjectFS) stat(op string, name string) (*objectFileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return &objectFileInfo{name: ".", isDir: true}, nil
+	}
+
+	md, err := ofs.store.ReadMetadata(ofs.ctx, ofs.url(name))
+
+	if err == nil {
+		return &objectFileInfo{name: path.Base(name), md: md}, nil
+	}
+
+	if !stderr.Is(err, errors.ErrObjectNotFound) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	objects, err := ofs.list(name)
+
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	if len(objects) == 0 {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &objectFileInfo{name: path.Base(name), isDir: true}, nil
+}
+
+func (ofs *ObjectFS) Open(name string) (fs.File, error) {
+	info, err := ofs.stat("open", name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if info.isDir {
+		return &objectDir{fs: ofs, name: name, info: info}, nil
+	}
+
+	return &objectFile{fs: ofs, u: ofs.url(name), info: info}, nil
+}
+
+func (ofs *ObjectFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := ofs.stat("stat", name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (ofs *ObjectFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	objects, err := ofs.list(name)
+
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	if len(objects) == 0 {
+		if info, err := ofs.stat("readdir", name); err != nil {
+			return nil, err
+		} else if !info.isDir {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: stderr.New("not a directory")}
+		}
+	}
+
+	var res []fs.DirEntry
+
+	for _, obj := range objects {
+		var entryName = path.Base(obj.URL.Path)
+
+		if obj.IsPrefix {
+			res = append(res, fs.FileInfoToDirEntry(&objectFileInfo{name: entryName, isDir: true}))
+		} else {
+			res = append(res, fs.FileInfoToDirEntry(&objectFileInfo{name: entryName, md: obj.Metadata}))
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name() < res[j].Name()
+	})
+
+	return res, nil
+}
+
+func (ofs *ObjectFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	r, err := ofs.store.Reader(ofs.ctx, ofs.url(name))
+
+	if stderr.Is(err, errors.ErrObjectNotFound) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+
+	return content, nil
+}
+
+func (ofs *ObjectFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+
+	if dir == "." {
+		return ofs, nil
+	}
+
+	return FS(ofs.ctx, ofs.store, ofs.url(dir)), nil
+}
+
+type objectFileInfo struct {
+	name  string
+	md    *types.ObjectMetadata
+	isDir bool
+}
+
+func (fi *objectFileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *objectFileInfo) Size() int64 {
+	if fi.md == nil {
+		return 0
+	}
+
+	return int64(fi.md.Size)
+}
+
+func (fi *objectFileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0555
+	}
+
+	return 0444
+}
+
+func (fi *objectFileInfo) ModTime() time.Time {
+	if fi.md == nil {
+		return time.Time{}
+	}
+
+	return fi.md.ModificationDate
+}
+
+func (fi *objectFileInfo) IsDir() bool {
+	return fi.isDir
+}
+
+func (fi *objectFileInfo) Sys() any {
+	return fi.md
+}
+
+type objectFile struct {
+	fs     *ObjectFS
+	u      *url.URL
+	info   *objectFileInfo
+	off    int64
+	ra     types.ReaderAt
+	r      io.Reader
+	closer io.Closer
+	closed bool
+}
+
+var (
+	_ io.Seeker   = (*objectFile)(nil)
+	_ io.ReaderAt = (*objectFile)(nil)
+)
+
+func (f *objectFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *objectFile) readerAt() (types.ReaderAt, error) {
+	if f.ra != nil {
+		return f.ra, nil
+	}
+
+	ra, err := f.fs.store.ReaderAt(f.fs.ctx, f.u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.ra = ra
+	return ra, nil
+}
+
+// reader streams sequentially from the start of the object and switches to
+// buffered ranged reads once the file has been seeked elsewhere
+func (f *objectFile) reader() (io.Reader, error) {
+	if f.r != nil {
+		return f.r, nil
+	}
+
+	if f.off == 0 {
+		r, err := f.fs.store.Reader(f.fs.ctx, f.u)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.r, f.closer = r, r
+		return f.r, nil
+	}
+
+	ra, err := f.readerAt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	f.r = bufio.NewReaderSize(io.NewSectionReader(ra, f.off, f.info.Size()-f.off), 1024*1024)
+	return f.r, nil
+}
+
+func (f *objectFile) resetReader() error {
+	var err error
+
+	if f.closer != nil {
+		err = f.closer.Close()
+	}
+
+	f.r, f.closer = nil, nil
+	return err
+}
+
+func (f *objectFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+
+	if f.off >= f.info.Size() {
+		return 0, io.EOF
+	}
+
+	r, err := f.reader()
+
+	if err != nil {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: err}
+	}
+
+	n, err := r.Read(p)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *objectFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+
+	if off < 0 {
+		return 0, &fs.PathError{Op: "readat", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+
+	if off >= f.info.Size() {
+		return 0, io.EOF
+	}
+
+	ra, err := f.readerAt()
+
+	if err != nil {
+		return 0, &fs.PathError{Op: "readat", Path: f.info.name, Err: err}
+	}
+
+	var (
+		want = min(int64(len(p)), f.info.Size()-off)
+		n    int
+	)
+
+	n, err = ra.ReadAt(p[:want], off)
+
+	if err != nil && !(stderr.Is(err, io.EOF) && int64(n) == want) {
+		return n, err
+	}
+
+	if int64(n) < int64(len(p)) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (f *objectFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, fs.ErrClosed
+	}
+
+	var abs int64
+
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = f.off + offset
+	case io.SeekEnd:
+		abs = f.info.Size() + offset
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+
+	if abs < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+
+	if abs != f.off {
+		if err := f.resetReader(); err != nil {
+			return 0, err
+		}
+
+		f.off = abs
+	}
+
+	return abs, nil
+}
+
+func (f *objectFile) Close() error {
+	if f.closed {
+		return fs.ErrClosed
+	}
+
+	f.closed = true
+
+	var err = f.resetReader()
+
+	if f.ra != nil {
+		err = stderr.Join(err, f.ra.Close())
+	}
+
+	return err
+}
+
+type objectDir struct {
+	fs      *ObjectFS
+	name    string
+	info    *objectFileInfo
+	entries []fs.DirEntry
+	loaded  bool
+	closed  bool
+}
+
+func (d *objectDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *objectDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: stderr.New("is a directory")}
+}
+
+func (d *objectDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, fs.ErrClosed
+	}
+
+	if !d.loaded {
+		entries, err := d.fs.ReadDir(d.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		d.entries = entries
+		d.loaded = true
+	}
+
+	if n <= 0 {
+		var res = d.entries
+		d.entries = nil
+		return res, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	var res = d.entries[:min(n, len(d.entries))]
+	d.entries = d.entries[len(res):]
+	return res, nil
+}
+
+func (d *objectDir) Close() error {
+	if d.closed {
+		return fs.ErrClosed
+	}
+
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,75 @@
+package objstr_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/agnosticeng/objstr"
+)
+
+var testFSFiles = map[string]string{
+	"a.txt":         "a",
+	"dir/b.txt":     "bb",
+	"dir/sub/c.txt": "ccc",
+	"empty.txt":     "",
+}
+
+func TestFS(t *testing.T) {
+	var prefixes = map[string]string{
+		"memory": "memory://testfs/",
+		"fs":     "file://" + t.TempDir() + "/",
+	}
+
+	for name, rawPrefix := range prefixes {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.Background()
+
+			store, err := objstr.NewObjectStore(ctx, objstr.Config{})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer store.Close()
+
+			prefix, err := url.Parse(rawPrefix)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var expected []string
+
+			for p, content := range testFSFiles {
+				writeObject(t, ctx, store, prefix.JoinPath(p), content)
+				expected = append(expected, p)
+			}
+
+			if err := fstest.TestFS(objstr.FS(ctx, store, prefix), expected...); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func writeObject(t *testing.T, ctx context.Context, store *objstr.ObjectStore, u *url.URL, content string) {
+	t.Helper()
+
+	w, err := store.Writer(ctx, u)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := strings.NewReader(content).WriteTo(w); err != nil {
+		w.Abort()
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
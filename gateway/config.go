@@ -0,0 +1,7 @@
+package gateway
+
+type HandlerConfig struct {
+	Mounts           map[string]string
+	BearerTokens     []string
+	ReadOnlyPrefixes []string
+}
@@ -0,0 +1,345 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	stderr "errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+)
+
+type mount struct {
+	path string
+	u    *url.URL
+}
+
+type Handler struct {
+	conf   HandlerConfig
+	store  *objstr.ObjectStore
+	mounts []mount
+}
+
+func NewHandler(store *objstr.ObjectStore, conf HandlerConfig) (*Handler, error) {
+	var mounts []mount
+
+	for p, rawUrl := range conf.Mounts {
+		u, err := url.Parse(rawUrl)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid mount url %s: %w", rawUrl, err)
+		}
+
+		mounts = append(mounts, mount{path: cleanPath(p), u: u})
+	}
+
+	if len(mounts) == 0 {
+		return nil, fmt.Errorf("at least one mount must be specified")
+	}
+
+	// longest mount paths first so that nested mounts take precedence
+	sort.Slice(mounts, func(i, j int) bool {
+		return len(mounts[i].path) > len(mounts[j].path)
+	})
+
+	return &Handler{
+		conf:   conf,
+		store:  store,
+		mounts: mounts,
+	}, nil
+}
+
+func cleanPath(p string) string {
+	return path.Join("/", p)
+}
+
+func hasPathPrefix(p string, prefix string) bool {
+	return prefix == "/" || p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+func (h *Handler) resolve(p string) (*mount, string, bool) {
+	var cleaned = cleanPath(p)
+
+	for i := range h.mounts {
+		if !hasPathPrefix(cleaned, h.mounts[i].path) {
+			continue
+		}
+
+		var name = strings.TrimPrefix(strings.TrimPrefix(cleaned, h.mounts[i].path), "/")
+
+		if len(name) == 0 {
+			name = "."
+		}
+
+		return &h.mounts[i], name, true
+	}
+
+	return nil, "", false
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if len(h.conf.BearerTokens) == 0 {
+		return true
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	if !found {
+		return false
+	}
+
+	for _, candidate := range h.conf.BearerTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) readOnly(p string) bool {
+	var cleaned = cleanPath(p)
+
+	for _, prefix := range h.conf.ReadOnlyPrefixes {
+		if hasPathPrefix(cleaned, cleanPath(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	m, name, found := h.resolve(r.URL.Path)
+
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, m, name)
+	case http.MethodPut, http.MethodDelete:
+		if h.readOnly(r.URL.Path) {
+			http.Error(w, "read-only prefix", http.StatusForbidden)
+			return
+		}
+
+		if name == "." || strings.HasSuffix(r.URL.Path, "/") {
+			http.Error(w, "object path required", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			h.put(w, r, m, name)
+		} else {
+			h.delete(w, r, m, name)
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) objectURL(m *mount, name string) *url.URL {
+	var u = *m.u
+	u.Path = path.Join("/", u.Path, name)
+	return &u
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, m *mount, name string) {
+	var ofs = objstr.FS(r.Context(), h.store, m.u)
+
+	f, err := ofs.Open(name)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if info.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			var u = *r.URL
+			u.Path = u.Path + "/"
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+			return
+		}
+
+		h.list(w, r, ofs, name)
+		return
+	}
+
+	if md, ok := info.Sys().(*types.ObjectMetadata); ok && len(md.ETag) > 0 {
+		w.Header().Set("ETag", quoteETag(md.ETag))
+	}
+
+	var contentType = mime.TypeByExtension(path.Ext(name))
+
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	// objstr.FS files are seekable, ranged reads being served via ReaderAt
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f.(io.ReadSeeker))
+}
+
+type listEntry struct {
+	Name             string    `json:"name"`
+	IsDir            bool      `json:"isDir"`
+	Size             uint64    `json:"size"`
+	ModificationDate time.Time `json:"modificationDate"`
+	ETag             string    `json:"etag,omitempty"`
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, ofs *objstr.ObjectFS, name string) {
+	entries, err := ofs.ReadDir(name)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var res = make([]listEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		var le = listEntry{
+			Name:  entry.Name(),
+			IsDir: entry.IsDir(),
+		}
+
+		if info, err := entry.Info(); err == nil {
+			if md, ok := info.Sys().(*types.ObjectMetadata); ok && md != nil {
+				le.Size = md.Size
+				le.ModificationDate = md.ModificationDate
+				le.ETag = md.ETag
+			}
+		}
+
+		res = append(res, le)
+	}
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	listTemplate.Execute(w, map[string]any{
+		"Path":    r.URL.Path,
+		"Entries": res,
+	})
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, m *mount, name string) {
+	writer, err := h.store.Writer(r.Context(), h.objectURL(m, name))
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := io.Copy(writer, r.Body); err != nil {
+		writer.Abort()
+		writeError(w, err)
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, m *mount, name string) {
+	if err := h.store.Delete(r.Context(), h.objectURL(m, name)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, "\"") || strings.HasPrefix(etag, "W/\"") {
+		return etag
+	}
+
+	return "\"" + etag + "\""
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case stderr.Is(err, fs.ErrNotExist), stderr.Is(err, errors.ErrObjectNotFound):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case stderr.Is(err, fs.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case stderr.Is(err, stderr.ErrUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var listTemplate = template.Must(template.New("list").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Path}}</title></head>
+<body>
+<h1>{{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th></tr>
+{{- range .Entries}}
+<tr>
+{{- if .IsDir}}
+<td><a href="{{.Name}}/">{{.Name}}/</a></td><td></td><td></td>
+{{- else}}
+<td><a href="{{.Name}}">{{.Name}}</a></td><td>{{.Size}}</td><td>{{if not .ModificationDate.IsZero}}{{.ModificationDate.Format "2006-01-02T15:04:05Z07:00"}}{{end}}</td>
+{{- end}}
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
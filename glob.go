@@ -0,0 +1,489 @@
+package objstr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agnosticeng/objstr/types"
+)
+
+const globMetaChars = "*?[{"
+
+// IsGlob reports whether s contains unescaped glob meta characters.
+func IsGlob(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case strings.IndexByte(globMetaChars, s[i]) >= 0:
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsGlobURL reports whether the path of s contains unescaped glob meta
+// characters; scheme and host are never treated as a pattern. Since "?"
+// starts a query string in http(s) and git URLs, those are only treated as
+// patterns when force is set. A meta character is matched literally when
+// escaped with "\", e.g. "s3://bucket/report\[final\].csv".
+func IsGlobURL(s string, force bool) bool {
+	if !force && hasQueryScheme(s) {
+		return false
+	}
+
+	_, p, err := splitGlob(s)
+
+	if err != nil {
+		// let Glob report the pattern in host
+		return true
+	}
+
+	return IsGlob(p)
+}
+
+// ParseLiteralURL parses a URL that IsGlobURL didn't report as a pattern,
+// resolving the escapes of glob meta characters in its path.
+func ParseLiteralURL(s string) (*url.URL, error) {
+	if hasQueryScheme(s) || !strings.Contains(s, "\\") {
+		return url.Parse(s)
+	}
+
+	base, p, err := splitGlob(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return globURL(base, UnescapeGlob(p)), nil
+}
+
+// UnescapeGlob resolves the "\" escapes of s.
+func UnescapeGlob(s string) string {
+	var res strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+
+		res.WriteByte(s[i])
+	}
+
+	return res.String()
+}
+
+// GlobBase returns the longest literal directory of the pattern, which is
+// where Glob starts listing, in the form returned by ResolvePrefix.
+func (os *ObjectStore) GlobBase(pattern string) (*url.URL, error) {
+	base, p, err := splitGlob(pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var literal = literalPrefix(p)
+	return os.ResolvePrefix(globURL(base, literal[:strings.LastIndexByte(literal, '/')+1]))
+}
+
+// GlobDestinations returns the destination of each object matched by the
+// pattern: dst joined with the object path relative to GlobBase, so that
+// objects with the same name in different directories are kept apart. It
+// fails when two objects still map to the same destination.
+func (os *ObjectStore) GlobDestinations(pattern string, objects []*types.Object, dst *url.URL) ([]*url.URL, error) {
+	base, err := os.GlobBase(pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res  = make([]*url.URL, 0, len(objects))
+		seen = make(map[string]*url.URL, len(objects))
+	)
+
+	for _, obj := range objects {
+		var u = *dst
+		u.Path = path.Join(dst.Path, strings.TrimPrefix(obj.URL.Path, base.Path))
+
+		if prev, found := seen[u.String()]; found {
+			return nil, fmt.Errorf("%s and %s would both be written to %s", prev, obj.URL, &u)
+		}
+
+		seen[u.String()] = obj.URL
+		res = append(res, &u)
+	}
+
+	return res, nil
+}
+
+func hasQueryScheme(s string) bool {
+	scheme, _, found := strings.Cut(s, "://")
+
+	if !found {
+		return false
+	}
+
+	for _, part := range strings.Split(strings.ToLower(scheme), "+") {
+		if part == "http" || part == "https" || part == "git" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitGlob separates "scheme://host" from the path of a glob pattern; the
+// pattern can't go through url.Parse since "?" is a glob meta character.
+func splitGlob(pattern string) (*url.URL, string, error) {
+	scheme, rest, found := strings.Cut(pattern, "://")
+
+	if !found {
+		return &url.URL{}, pattern, nil
+	}
+
+	var host, p = rest, ""
+
+	if idx := strings.IndexByte(rest, '/'); idx >= 0 {
+		host, p = rest[:idx], rest[idx:]
+	}
+
+	if IsGlob(host) {
+		return nil, "", fmt.Errorf("glob patterns are not supported in host: %s", pattern)
+	}
+
+	u, err := url.Parse(scheme + "://" + host)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return u, p, nil
+}
+
+// escapeGlob escapes the meta characters of a literal path.
+func escapeGlob(s string) string {
+	var res strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || strings.IndexByte(globMetaChars, s[i]) >= 0 {
+			res.WriteByte('\\')
+		}
+
+		res.WriteByte(s[i])
+	}
+
+	return res.String()
+}
+
+// compileGlob translates a glob pattern into an anchored regular expression.
+// "*", "?" and "[]" never match "/", "**" matches across path segments and
+// "{a,b}" alternatives can be nested.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	expr, rest, err := translateGlob(pattern, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid glob pattern: %s", pattern)
+	}
+
+	return regexp.Compile("^" + expr + "$")
+}
+
+func translateGlob(pattern string, inBraces bool) (string, string, error) {
+	var res strings.Builder
+
+	for len(pattern) > 0 {
+		var c = pattern[0]
+
+		switch {
+		case c == '\\' && len(pattern) > 1:
+			res.WriteString(regexp.QuoteMeta(pattern[1:2]))
+			pattern = pattern[2:]
+
+		case strings.HasPrefix(pattern, "**/"):
+			res.WriteString("(?:.*/)?")
+			pattern = pattern[3:]
+
+		case strings.HasPrefix(pattern, "**"):
+			res.WriteString(".*")
+			pattern = pattern[2:]
+
+		case c == '*':
+			res.WriteString("[^/]*")
+			pattern = pattern[1:]
+
+		case c == '?':
+			res.WriteString("[^/]")
+			pattern = pattern[1:]
+
+		case c == '[':
+			end := strings.IndexByte(pattern[1:], ']')
+
+			if end < 0 {
+				return "", "", fmt.Errorf("unterminated character class in glob pattern")
+			}
+
+			var class = pattern[1 : end+1]
+
+			if len(class) > 0 && (class[0] == '!' || class[0] == '^') {
+				class = "^" + class[1:]
+			}
+
+			res.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			pattern = pattern[end+2:]
+
+		case c == '{':
+			var alternatives []string
+
+			pattern = pattern[1:]
+
+			for {
+				alt, rest, err := translateGlob(pattern, true)
+
+				if err != nil {
+					return "", "", err
+				}
+
+				if len(rest) == 0 {
+					return "", "", fmt.Errorf("unterminated alternatives in glob pattern")
+				}
+
+				alternatives = append(alternatives, alt)
+				pattern = rest[1:]
+
+				if rest[0] == '}' {
+					break
+				}
+			}
+
+			res.WriteString("(?:" + strings.Join(alternatives, "|") + ")")
+
+		case inBraces && (c == ',' || c == '}'):
+			return res.String(), pattern, nil
+
+		default:
+			res.WriteString(regexp.QuoteMeta(pattern[0:1]))
+			pattern = pattern[1:]
+		}
+	}
+
+	return res.String(), "", nil
+}
+
+// literalPrefix returns the part of the pattern before its first meta
+// character, with escapes resolved.
+func literalPrefix(pattern string) string {
+	var res strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			res.WriteByte(pattern[i])
+		case strings.IndexByte(globMetaChars, pattern[i]) >= 0:
+			return res.String()
+		default:
+			res.WriteByte(pattern[i])
+		}
+	}
+
+	return res.String()
+}
+
+// Glob returns the objects matching the pattern. Listing starts at the
+// longest literal directory of the pattern and walks the following segments
+// with "/" delimited listings, so that only matching prefixes are listed; a
+// recursive listing is used from the first segment containing "**" or a
+// "/" inside alternatives.
+func (os *ObjectStore) Glob(ctx context.Context, pattern string) ([]*types.Object, error) {
+	base, p, err := splitGlob(pattern)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsGlob(p) {
+		var u = globURL(base, UnescapeGlob(p))
+
+		md, err := os.ReadMetadata(ctx, u)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []*types.Object{{URL: u, Metadata: md}}, nil
+	}
+
+	var (
+		literal = literalPrefix(p)
+		dir     = literal[:strings.LastIndexByte(literal, '/')+1]
+		rest    = p[len(escapedPrefixLen(p, dir)):]
+	)
+
+	// the directory is resolved to the form of listed paths, e.g. absolute
+	// paths for a relative fs pattern, and the pattern is matched against it
+	resolved, err := os.ResolvePrefix(globURL(base, dir))
+
+	if err != nil {
+		return nil, err
+	}
+
+	base, dir = resolved, resolved.Path
+
+	re, err := compileGlob(escapeGlob(dir) + rest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		segments = splitGlobSegments(rest)
+		prefixes = []string{dir}
+	)
+
+	for i, segment := range segments {
+		var (
+			last     = i == len(segments)-1
+			segRe, _ = compileGlob(segment)
+			next     []string
+		)
+
+		if strings.Contains(segment, "**") || strings.Contains(segment, "/") {
+			return os.globRecursive(ctx, base, prefixes, re)
+		}
+
+		if !IsGlob(segment) && !last {
+			for _, prefix := range prefixes {
+				next = append(next, prefix+literalPrefix(segment)+"/")
+			}
+
+			prefixes = next
+			continue
+		}
+
+		var res []*types.Object
+
+		for _, prefix := range prefixes {
+			objects, err := os.ListPrefix(ctx, globURL(base, prefix), types.WithDelimiter("/"))
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, obj := range objects {
+				var name = strings.TrimSuffix(strings.TrimPrefix(obj.URL.Path, prefix), "/")
+
+				if !segRe.MatchString(name) {
+					continue
+				}
+
+				switch {
+				case last && !obj.IsPrefix:
+					res = append(res, obj)
+				case !last && obj.IsPrefix:
+					next = append(next, obj.URL.Path)
+				}
+			}
+		}
+
+		if last {
+			sortObjects(res)
+			return res, nil
+		}
+
+		prefixes = next
+	}
+
+	return nil, nil
+}
+
+func (os *ObjectStore) globRecursive(ctx context.Context, base *url.URL, prefixes []string, re *regexp.Regexp) ([]*types.Object, error) {
+	var res []*types.Object
+
+	for _, prefix := range prefixes {
+		objects, err := os.ListPrefix(ctx, globURL(base, prefix))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects {
+			if re.MatchString(obj.URL.Path) {
+				res = append(res, obj)
+			}
+		}
+	}
+
+	sortObjects(res)
+	return res, nil
+}
+
+// splitGlobSegments splits on "/" outside of alternatives; a segment keeps
+// its "/" when an alternative spans several path segments.
+func splitGlobSegments(p string) []string {
+	var (
+		res   []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				res = append(res, p[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(res, p[start:])
+}
+
+// escapedPrefixLen returns the prefix of the raw pattern that resolves to the
+// given literal, accounting for escape characters.
+func escapedPrefixLen(p string, literal string) string {
+	var n int
+
+	for i := 0; i < len(p) && n < len(literal); i++ {
+		if p[i] == '\\' {
+			i++
+		}
+
+		n++
+
+		if n == len(literal) {
+			return p[:i+1]
+		}
+	}
+
+	return ""
+}
+
+func globURL(base *url.URL, p string) *url.URL {
+	var u = *base
+	u.Path = p
+	return &u
+}
+
+func sortObjects(objects []*types.Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].URL.Path < objects[j].URL.Path
+	})
+}
@@ -0,0 +1,253 @@
+package manifest
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/types"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+var csvHeader = []string{"url", "key", "size", "modification_date", "etag", "checksum"}
+
+// Entry describes an object at export time. Key is the path of the object
+// relative to the exported prefix, Checksum is "<algorithm>:<hex digest>".
+type Entry struct {
+	URL              string    `json:"url"`
+	Key              string    `json:"key"`
+	Size             uint64    `json:"size"`
+	ModificationDate time.Time `json:"modification_date"`
+	ETag             string    `json:"etag,omitempty"`
+	Checksum         string    `json:"checksum,omitempty"`
+}
+
+// NewEntry keys the object by its path relative to prefix, which must be in
+// the form returned by ObjectStore.ResolvePrefix.
+func NewEntry(prefix *url.URL, obj *types.Object) *Entry {
+	return &Entry{
+		URL:              obj.URL.String(),
+		Key:              strings.TrimPrefix(strings.TrimPrefix(obj.URL.Path, prefix.Path), "/"),
+		Size:             obj.Metadata.Size,
+		ModificationDate: obj.Metadata.ModificationDate,
+		ETag:             obj.Metadata.ETag,
+	}
+}
+
+func (e *Entry) ParseURL() (*url.URL, error) {
+	return url.Parse(e.URL)
+}
+
+// Destination joins the entry key, or the base name of src when the key is
+// empty, to prefix. The key is cleaned as a rooted path so that a hand-edited
+// manifest can't escape prefix with ".." elements.
+func (e *Entry) Destination(prefix *url.URL, src *url.URL) *url.URL {
+	var (
+		res = *prefix
+		key = e.Key
+	)
+
+	if len(key) == 0 {
+		key = path.Base(src.Path)
+	}
+
+	res.Path = path.Join(prefix.Path, path.Clean("/"+key))
+	res.RawPath = ""
+	return &res
+}
+
+// SplitChecksum returns the algorithm and digest of the entry checksum.
+func (e *Entry) SplitChecksum() (string, string, bool) {
+	algorithm, digest, found := strings.Cut(e.Checksum, ":")
+	return algorithm, digest, found && len(algorithm) > 0 && len(digest) > 0
+}
+
+// FormatFromPath picks the CSV format for .csv paths and NDJSON otherwise.
+func FormatFromPath(p string) string {
+	if strings.EqualFold(path.Ext(p), ".csv") {
+		return FormatCSV
+	}
+
+	return FormatNDJSON
+}
+
+type Writer struct {
+	format string
+	json   *json.Encoder
+	csv    *csv.Writer
+}
+
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		return &Writer{format: format, json: json.NewEncoder(w)}, nil
+
+	case FormatCSV:
+		var cw = csv.NewWriter(w)
+
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+
+		return &Writer{format: format, csv: cw}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown manifest format: %s", format)
+	}
+}
+
+func (mw *Writer) Write(e *Entry) error {
+	if mw.format == FormatNDJSON {
+		return mw.json.Encode(e)
+	}
+
+	var modificationDate string
+
+	if !e.ModificationDate.IsZero() {
+		modificationDate = e.ModificationDate.Format(time.RFC3339Nano)
+	}
+
+	return mw.csv.Write([]string{
+		e.URL,
+		e.Key,
+		strconv.FormatUint(e.Size, 10),
+		modificationDate,
+		e.ETag,
+		e.Checksum,
+	})
+}
+
+func (mw *Writer) Flush() error {
+	if mw.csv != nil {
+		mw.csv.Flush()
+		return mw.csv.Error()
+	}
+
+	return nil
+}
+
+func Read(r io.Reader, format string) ([]*Entry, error) {
+	switch format {
+	case FormatNDJSON:
+		return readNDJSON(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown manifest format: %s", format)
+	}
+}
+
+func readNDJSON(r io.Reader) ([]*Entry, error) {
+	var (
+		res     []*Entry
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line++
+
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var e Entry
+
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+
+		res = append(res, &e)
+	}
+
+	return res, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]*Entry, error) {
+	var cr = csv.NewReader(r)
+
+	records, err := cr.ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var columns = make(map[string]int)
+
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+
+	if _, found := columns["url"]; !found {
+		return nil, fmt.Errorf("manifest has no url column")
+	}
+
+	var (
+		res []*Entry
+		get = func(record []string, name string) string {
+			if i, found := columns[name]; found && i < len(record) {
+				return record[i]
+			}
+
+			return ""
+		}
+	)
+
+	for i, record := range records[1:] {
+		var e = Entry{
+			URL:      get(record, "url"),
+			Key:      get(record, "key"),
+			ETag:     get(record, "etag"),
+			Checksum: get(record, "checksum"),
+		}
+
+		if s := get(record, "size"); len(s) > 0 {
+			if e.Size, err = strconv.ParseUint(s, 10, 64); err != nil {
+				return nil, fmt.Errorf("manifest line %d: %w", i+2, err)
+			}
+		}
+
+		if s := get(record, "modification_date"); len(s) > 0 {
+			if e.ModificationDate, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, fmt.Errorf("manifest line %d: %w", i+2, err)
+			}
+		}
+
+		res = append(res, &e)
+	}
+
+	return res, nil
+}
+
+// Load reads a manifest stored at any objstr URL, its format being picked
+// from the extension.
+func Load(ctx context.Context, store *objstr.ObjectStore, u *url.URL) ([]*Entry, error) {
+	r, err := store.Reader(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return Read(r, FormatFromPath(u.Path))
+}
@@ -0,0 +1,69 @@
+package objstr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+)
+
+type MovePrefixOptions struct {
+	// MaxConcurrentRequests bounds the number of objects moved at once,
+	// DefaultMaxConcurrentRequests is used when zero.
+	MaxConcurrentRequests int
+	// CopyOptions apply to objects that can't be renamed, see Move.
+	CopyOptions []types.CopyOption
+	// OnResult is called once per object, possibly concurrently.
+	OnResult func(MoveResult)
+}
+
+type MoveResult struct {
+	Src *url.URL
+	Dst *url.URL
+	Err error
+}
+
+// MovePrefix moves every object under srcPrefix to the same relative path
+// under dstPrefix. Objects are moved independently: a failure leaves its
+// source in place and doesn't stop the other moves, so running it again
+// picks up the remaining objects.
+func (os *ObjectStore) MovePrefix(ctx context.Context, srcPrefix *url.URL, dstPrefix *url.URL, opts *MovePrefixOptions) error {
+	if opts == nil {
+		opts = &MovePrefixOptions{}
+	}
+
+	if sameLocation(srcPrefix, dstPrefix) {
+		return fmt.Errorf("source and destination are the same prefix: %s", srcPrefix.String())
+	}
+
+	objects, err := os.ListPrefix(ctx, srcPrefix)
+
+	if err != nil {
+		return err
+	}
+
+	var mapper = iter.Mapper[*types.Object, error]{
+		MaxGoroutines: maxConcurrentRequests(opts.MaxConcurrentRequests),
+	}
+
+	var errs = mapper.Map(objects, func(o **types.Object) error {
+		var res = MoveResult{Src: (*o).URL}
+
+		res.Dst, res.Err = types.GenerateDstURL(dstPrefix, srcPrefix, res.Src)
+
+		if res.Err == nil {
+			res.Err = os.Move(ctx, res.Src, res.Dst, opts.CopyOptions...)
+		}
+
+		if opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+
+		return res.Err
+	})
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package objstr_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/agnosticeng/objstr"
+)
+
+func TestMoveOntoItself(t *testing.T) {
+	var ctx = context.Background()
+
+	store, err := objstr.NewObjectStore(ctx, objstr.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer store.Close()
+
+	u, err := url.Parse("memory://move/p/x")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeObject(t, ctx, store, u, "x")
+
+	if err := store.Move(ctx, u, u); err == nil {
+		t.Fatal("moving an object onto itself must fail")
+	}
+
+	var prefix = u.JoinPath("..", "/")
+
+	if err := store.MovePrefix(ctx, prefix, prefix, nil); err == nil {
+		t.Fatal("moving a prefix onto itself must fail")
+	}
+
+	if _, err := store.ReadMetadata(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+}
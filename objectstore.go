@@ -2,13 +2,18 @@ package objstr
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/url"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"dario.cat/mergo"
 	"github.com/agnosticeng/objstr/backend"
+	"github.com/agnosticeng/objstr/backend/impl/archive"
 	"github.com/agnosticeng/objstr/backend/impl/fs"
 	"github.com/agnosticeng/objstr/backend/impl/git"
 	"github.com/agnosticeng/objstr/backend/impl/http"
@@ -98,6 +103,18 @@ func NewObjectStore(ctx context.Context, conf Config) (*ObjectStore, error) {
 		backends[strings.ToLower(scheme)] = backend
 	}
 
+	for _, scheme := range lo.Keys(backends) {
+		for _, format := range []string{archive.FormatZip, archive.FormatTar} {
+			backend, err := archive.NewArchiveBackend(ctx, archive.ArchiveBackendConfig{Format: format}, backends[scheme])
+
+			if err != nil {
+				return nil, err
+			}
+
+			backends[format+"+"+scheme] = backend
+		}
+	}
+
 	be, found := backends[conf.DefaultBackend]
 
 	if !found {
@@ -154,6 +171,31 @@ func (os *ObjectStore) ListPrefix(ctx context.Context, u *url.URL, optsFunc ...t
 	return objects, nil
 }
 
+// ResolvePrefix returns the prefix in the form of the object URLs returned by
+// ListPrefix, so that object paths can be made relative to it.
+func (os *ObjectStore) ResolvePrefix(u *url.URL) (*url.URL, error) {
+	be, err := os.getBackend(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, ok := be.(backend.PathResolver)
+
+	if !ok {
+		return u, nil
+	}
+
+	res, err := resolver.ResolvePath(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Scheme = u.Scheme
+	return res, nil
+}
+
 func (os *ObjectStore) ReadMetadata(ctx context.Context, u *url.URL) (*types.ObjectMetadata, error) {
 	backend, err := os.getBackend(u)
 
@@ -184,14 +226,14 @@ func (os *ObjectStore) ReaderAt(ctx context.Context, u *url.URL) (types.ReaderAt
 	return backend.ReaderAt(ctx, u)
 }
 
-func (os *ObjectStore) Writer(ctx context.Context, u *url.URL) (types.Writer, error) {
+func (os *ObjectStore) Writer(ctx context.Context, u *url.URL, optsFunc ...types.WriteOption) (types.Writer, error) {
 	backend, err := os.getBackend(u)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return backend.Writer(ctx, u)
+	return backend.Writer(ctx, u, optsFunc...)
 }
 
 func (os *ObjectStore) Delete(ctx context.Context, u *url.URL) error {
@@ -204,7 +246,39 @@ func (os *ObjectStore) Delete(ctx context.Context, u *url.URL) error {
 	return backend.Delete(ctx, u)
 }
 
-func (os *ObjectStore) copy(ctx context.Context, srcBackend backend.Backend, dstBackend backend.Backend, src *url.URL, dst *url.URL) error {
+func (os *ObjectStore) copy(
+	ctx context.Context,
+	srcBackend backend.Backend,
+	dstBackend backend.Backend,
+	src *url.URL,
+	dst *url.URL,
+	opts *types.CopyOptions,
+) error {
+	if err := os.transfer(ctx, srcBackend, dstBackend, src, dst, opts); err != nil {
+		return err
+	}
+
+	if len(opts.Verify) > 0 {
+		return os.verify(ctx, src, dst, CompareMode(opts.Verify))
+	}
+
+	return nil
+}
+
+func (os *ObjectStore) transfer(
+	ctx context.Context,
+	srcBackend backend.Backend,
+	dstBackend backend.Backend,
+	src *url.URL,
+	dst *url.URL,
+	opts *types.CopyOptions,
+) error {
+	if len(opts.ResumeStateDir) > 0 {
+		if resumableBackend, ok := dstBackend.(backend.ResumableBackend); ok {
+			return os.resumableCopy(ctx, srcBackend, resumableBackend, src, dst, opts)
+		}
+	}
+
 	buf := make([]byte, os.conf.CopyBufferSize)
 
 	srcReader, err := srcBackend.Reader(ctx, src)
@@ -222,13 +296,39 @@ func (os *ObjectStore) copy(ctx context.Context, srcBackend backend.Backend, dst
 	}
 
 	if _, err := io.CopyBuffer(dstWriter, srcReader, buf); err != nil {
+		dstWriter.Abort()
 		return err
 	}
 
 	return dstWriter.Close()
 }
 
-func (os *ObjectStore) Copy(ctx context.Context, src *url.URL, dst *url.URL) error {
+func (os *ObjectStore) resumableCopy(
+	ctx context.Context,
+	srcBackend backend.Backend,
+	dstBackend backend.ResumableBackend,
+	src *url.URL,
+	dst *url.URL,
+	opts *types.CopyOptions,
+) error {
+	md, err := srcBackend.ReadMetadata(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	srcReader, err := srcBackend.ReaderAt(ctx, src)
+
+	if err != nil {
+		return err
+	}
+
+	defer srcReader.Close()
+
+	return dstBackend.ResumableUpload(ctx, dst, srcReader, &types.Object{URL: src, Metadata: md}, resumeStatePath(opts.ResumeStateDir, src, dst))
+}
+
+func (os *ObjectStore) Copy(ctx context.Context, src *url.URL, dst *url.URL, optFuncs ...types.CopyOption) error {
 	srcBackend, err := os.getBackend(src)
 
 	if err != nil {
@@ -241,10 +341,18 @@ func (os *ObjectStore) Copy(ctx context.Context, src *url.URL, dst *url.URL) err
 		return err
 	}
 
-	return os.copy(ctx, srcBackend, dstBackend, src, dst)
+	return os.copy(ctx, srcBackend, dstBackend, src, dst, types.NewCopyOptions(optFuncs...))
 }
 
-func (os *ObjectStore) Move(ctx context.Context, src *url.URL, dst *url.URL) error {
+// Move copies src to dst then deletes src, or renames it when both are on a
+// MoveableBackend. The source is left untouched when the copy or its
+// verification fails. Moving an object onto itself is rejected since the
+// delete would remove the only copy.
+func (os *ObjectStore) Move(ctx context.Context, src *url.URL, dst *url.URL, optFuncs ...types.CopyOption) error {
+	if sameLocation(src, dst) {
+		return fmt.Errorf("source and destination are the same object: %s", src.String())
+	}
+
 	srcBackend, err := os.getBackend(src)
 
 	if err != nil {
@@ -263,13 +371,22 @@ func (os *ObjectStore) Move(ctx context.Context, src *url.URL, dst *url.URL) err
 		}
 	}
 
-	if err := os.copy(ctx, srcBackend, dstBackend, src, dst); err != nil {
+	if err := os.copy(ctx, srcBackend, dstBackend, src, dst, types.NewCopyOptions(optFuncs...)); err != nil {
 		return err
 	}
 
 	return srcBackend.Delete(ctx, src)
 }
 
+// sameLocation reports whether both URLs name the same object or prefix.
+func sameLocation(a *url.URL, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Host, b.Host) &&
+		a.User.String() == b.User.String() &&
+		path.Clean("/"+a.Path) == path.Clean("/"+b.Path) &&
+		a.RawQuery == b.RawQuery
+}
+
 func (os *ObjectStore) Close() error {
 	var res *multierror.Error
 
@@ -281,3 +398,8 @@ func (os *ObjectStore) Close() error {
 
 	return res.ErrorOrNil()
 }
+
+func resumeStatePath(dir string, src *url.URL, dst *url.URL) string {
+	var h = sha256.Sum256([]byte(src.String() + "\n" + dst.String()))
+	return filepath.Join(dir, hex.EncodeToString(h[:])+".json")
+}
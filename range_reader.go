@@ -0,0 +1,76 @@
+package objstr
+
+import (
+	"bufio"
+	"context"
+	stderr "errors"
+	"io"
+	"net/url"
+
+	"github.com/agnosticeng/objstr/types"
+)
+
+// rangeReadBufferSize bounds the size of the reads issued to the ReaderAt,
+// which some backends serve with one request each.
+const rangeReadBufferSize = 1024 * 1024
+
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+// RangeReader returns a reader over length bytes starting at offset, or up
+// to the end of the object when length is negative. It is served by
+// ReaderAt; backends without ReaderAt support fall back to a sequential
+// Reader that skips the first offset bytes.
+func (os *ObjectStore) RangeReader(ctx context.Context, u *url.URL, offset int64, length int64) (types.Reader, error) {
+	ra, err := os.ReaderAt(ctx, u)
+
+	if stderr.Is(err, stderr.ErrUnsupported) {
+		return os.skippingReader(ctx, u, offset, length)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if length < 0 {
+		md, err := os.ReadMetadata(ctx, u)
+
+		if err != nil {
+			ra.Close()
+			return nil, err
+		}
+
+		length = max(int64(md.Size)-offset, 0)
+	}
+
+	// a buffered reader keeps ReadFrom implementations, such as the one of
+	// os.File, from reading the section in small chunks
+	return &rangeReader{
+		Reader: bufio.NewReaderSize(io.NewSectionReader(ra, offset, length), int(min(length, rangeReadBufferSize))),
+		Closer: ra,
+	}, nil
+}
+
+func (os *ObjectStore) skippingReader(ctx context.Context, u *url.URL, offset int64, length int64) (types.Reader, error) {
+	r, err := os.Reader(ctx, u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.CopyN(io.Discard, r, offset); err != nil && !stderr.Is(err, io.EOF) {
+		r.Close()
+		return nil, err
+	}
+
+	if length < 0 {
+		return r, nil
+	}
+
+	return &rangeReader{
+		Reader: io.LimitReader(r, length),
+		Closer: r,
+	}, nil
+}
@@ -0,0 +1,314 @@
+package s3server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	maxClockSkew     = 15 * time.Minute
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+var errContentSHA256Mismatch = errors.New("x-amz-content-sha256 does not match the request payload")
+
+type sigV4Request struct {
+	accessKeyId   string
+	scope         string
+	date          string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	payloadHash   string
+	presigned     bool
+}
+
+// authenticate verifies the AWS Signature Version 4 of the request, either
+// from the Authorization header or from presigned query parameters. When the
+// payload hash is signed, the request body is wrapped so that a mismatch is
+// reported as a read error once the body has been consumed.
+func (h *Handler) authenticate(r *http.Request) *s3Error {
+	if len(h.conf.Credentials) == 0 && h.conf.Anonymous {
+		return nil
+	}
+
+	var (
+		sr  *sigV4Request
+		err *s3Error
+	)
+
+	switch {
+	case strings.HasPrefix(r.Header.Get("Authorization"), sigV4Algorithm+" "):
+		sr, err = parseAuthorizationHeader(r)
+	case r.URL.Query().Get("X-Amz-Algorithm") == sigV4Algorithm:
+		sr, err = parsePresignedQuery(r)
+	case len(r.Header.Get("Authorization")) > 0 || len(r.URL.Query().Get("X-Amz-Algorithm")) > 0:
+		return errUnsupportedSignature
+	default:
+		return errAccessDenied
+	}
+
+	if err != nil {
+		return err
+	}
+
+	secret, found := h.conf.Credentials[sr.accessKeyId]
+
+	if !found {
+		return errInvalidAccessKeyId
+	}
+
+	var canonicalRequest = strings.Join([]string{
+		r.Method,
+		canonicalURI(r),
+		canonicalQuery(r, sr.presigned),
+		canonicalHeaders(r, sr.signedHeaders),
+		strings.Join(sr.signedHeaders, ";"),
+		sr.payloadHash,
+	}, "\n")
+
+	var stringToSign = strings.Join([]string{
+		sigV4Algorithm,
+		sr.amzDate.Format(sigV4TimeFormat),
+		sr.scope,
+		hex.EncodeToString(sha256Sum([]byte(canonicalRequest))),
+	}, "\n")
+
+	var (
+		signingKey = deriveSigningKey(secret, sr.date, sr.region, sr.service)
+		expected   = hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
+	)
+
+	if !hmac.Equal([]byte(expected), []byte(sr.signature)) {
+		return errSignatureDoesNotMatch
+	}
+
+	if !sr.presigned && sr.payloadHash != unsignedPayload {
+		expectedHash, decodeErr := hex.DecodeString(sr.payloadHash)
+
+		if decodeErr != nil {
+			return errUnsupportedSignature
+		}
+
+		r.Body = &hashVerifyingReader{
+			r:        r.Body,
+			h:        sha256.New(),
+			expected: expectedHash,
+		}
+	}
+
+	return nil
+}
+
+func parseAuthorizationHeader(r *http.Request) (*sigV4Request, *s3Error) {
+	var (
+		sr     sigV4Request
+		fields = strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), sigV4Algorithm+" "), ",")
+		cred   string
+	)
+
+	for _, field := range fields {
+		k, v, found := strings.Cut(strings.TrimSpace(field), "=")
+
+		if !found {
+			return nil, errAuthorizationHeaderMalformed
+		}
+
+		switch k {
+		case "Credential":
+			cred = v
+		case "SignedHeaders":
+			sr.signedHeaders = strings.Split(v, ";")
+		case "Signature":
+			sr.signature = v
+		}
+	}
+
+	if len(cred) == 0 || len(sr.signedHeaders) == 0 || len(sr.signature) == 0 {
+		return nil, errAuthorizationHeaderMalformed
+	}
+
+	if err := sr.parseCredential(cred); err != nil {
+		return nil, err
+	}
+
+	var rawDate = r.Header.Get("X-Amz-Date")
+
+	if len(rawDate) == 0 {
+		return nil, errAuthorizationHeaderMalformed
+	}
+
+	amzDate, err := time.Parse(sigV4TimeFormat, rawDate)
+
+	if err != nil {
+		return nil, errAuthorizationHeaderMalformed
+	}
+
+	if d := time.Since(amzDate); d > maxClockSkew || d < -maxClockSkew {
+		return nil, errRequestTimeTooSkewed
+	}
+
+	sr.amzDate = amzDate
+	sr.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+
+	if len(sr.payloadHash) == 0 {
+		return nil, errAuthorizationHeaderMalformed
+	}
+
+	if strings.HasPrefix(sr.payloadHash, "STREAMING-") {
+		return nil, errUnsupportedSignature
+	}
+
+	return &sr, nil
+}
+
+func parsePresignedQuery(r *http.Request) (*sigV4Request, *s3Error) {
+	var (
+		sr sigV4Request
+		q  = r.URL.Query()
+	)
+
+	sr.presigned = true
+	sr.signature = q.Get("X-Amz-Signature")
+	sr.signedHeaders = strings.Split(q.Get("X-Amz-SignedHeaders"), ";")
+	sr.payloadHash = unsignedPayload
+
+	if len(sr.signature) == 0 || len(q.Get("X-Amz-SignedHeaders")) == 0 {
+		return nil, errAuthorizationQueryParametersError
+	}
+
+	if err := sr.parseCredential(q.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+
+	amzDate, err := time.Parse(sigV4TimeFormat, q.Get("X-Amz-Date"))
+
+	if err != nil {
+		return nil, errAuthorizationQueryParametersError
+	}
+
+	expires, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return nil, errAuthorizationQueryParametersError
+	}
+
+	if time.Now().After(amzDate.Add(time.Duration(expires) * time.Second)) {
+		return nil, errExpiredRequest
+	}
+
+	sr.amzDate = amzDate
+	return &sr, nil
+}
+
+func (sr *sigV4Request) parseCredential(cred string) *s3Error {
+	var parts = strings.Split(cred, "/")
+
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return errAuthorizationHeaderMalformed
+	}
+
+	sr.accessKeyId = parts[0]
+	sr.date = parts[1]
+	sr.region = parts[2]
+	sr.service = parts[3]
+	sr.scope = strings.Join(parts[1:], "/")
+	return nil
+}
+
+func canonicalURI(r *http.Request) string {
+	var uri = r.RequestURI
+
+	if idx := strings.IndexByte(uri, '?'); idx >= 0 {
+		uri = uri[:idx]
+	}
+
+	if len(uri) == 0 {
+		return "/"
+	}
+
+	return uri
+}
+
+func canonicalQuery(r *http.Request, presigned bool) string {
+	var q = r.URL.Query()
+
+	if presigned {
+		q.Del("X-Amz-Signature")
+	}
+
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var lines []string
+
+	for _, name := range signedHeaders {
+		var value string
+
+		switch {
+		case name == "host":
+			value = r.Host
+		case name == "content-length" && len(r.Header.Get(name)) == 0:
+			value = strconv.FormatInt(r.ContentLength, 10)
+		default:
+			value = strings.Join(r.Header.Values(name), ",")
+		}
+
+		lines = append(lines, name+":"+strings.Join(strings.Fields(value), " "))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func deriveSigningKey(secret string, date string, region string, service string) []byte {
+	var key = hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+func hmacSHA256(key []byte, data []byte) []byte {
+	var h = hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func sha256Sum(data []byte) []byte {
+	var sum = sha256.Sum256(data)
+	return sum[:]
+}
+
+type hashVerifyingReader struct {
+	r        io.ReadCloser
+	h        hash.Hash
+	expected []byte
+}
+
+func (hr *hashVerifyingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+
+	if err == io.EOF && !hmac.Equal(hr.h.Sum(nil), hr.expected) {
+		return n, errContentSHA256Mismatch
+	}
+
+	return n, err
+}
+
+func (hr *hashVerifyingReader) Close() error {
+	return hr.r.Close()
+}
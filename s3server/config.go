@@ -0,0 +1,10 @@
+package s3server
+
+type HandlerConfig struct {
+	Buckets     map[string]string
+	Credentials map[string]string
+	// Anonymous allows unsigned requests when no credentials are configured;
+	// without it, a handler can't be created without credentials.
+	Anonymous           bool
+	MultipartStagingURL string
+}
@@ -0,0 +1,88 @@
+package s3server
+
+import (
+	"encoding/xml"
+	stderr "errors"
+	"io/fs"
+	"net/http"
+
+	"github.com/agnosticeng/objstr/errors"
+)
+
+type s3Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+func (e *s3Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var (
+	errAccessDenied                      = &s3Error{"AccessDenied", "Access Denied", http.StatusForbidden}
+	errAuthorizationHeaderMalformed      = &s3Error{"AuthorizationHeaderMalformed", "The authorization header is malformed", http.StatusBadRequest}
+	errAuthorizationQueryParametersError = &s3Error{"AuthorizationQueryParametersError", "The authorization query parameters are malformed", http.StatusBadRequest}
+	errBadDigest                         = &s3Error{"BadDigest", "The Content-MD5 you specified did not match what we received", http.StatusBadRequest}
+	errExpiredRequest                    = &s3Error{"AccessDenied", "Request has expired", http.StatusForbidden}
+	errInternalError                     = &s3Error{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+	errInvalidAccessKeyId                = &s3Error{"InvalidAccessKeyId", "The AWS access key Id you provided does not exist in our records", http.StatusForbidden}
+	errInvalidArgument                   = &s3Error{"InvalidArgument", "Invalid Argument", http.StatusBadRequest}
+	errInvalidPart                       = &s3Error{"InvalidPart", "One or more of the specified parts could not be found", http.StatusBadRequest}
+	errInvalidPartOrder                  = &s3Error{"InvalidPartOrder", "The list of parts was not in ascending order", http.StatusBadRequest}
+	errMalformedXML                      = &s3Error{"MalformedXML", "The XML you provided was not well-formed", http.StatusBadRequest}
+	errMethodNotAllowed                  = &s3Error{"MethodNotAllowed", "The specified method is not allowed against this resource", http.StatusMethodNotAllowed}
+	errNoSuchBucket                      = &s3Error{"NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound}
+	errNoSuchKey                         = &s3Error{"NoSuchKey", "The specified key does not exist", http.StatusNotFound}
+	errNoSuchUpload                      = &s3Error{"NoSuchUpload", "The specified multipart upload does not exist", http.StatusNotFound}
+	errNotImplemented                    = &s3Error{"NotImplemented", "A header you provided implies functionality that is not implemented", http.StatusNotImplemented}
+	errPreconditionFailed                = &s3Error{"PreconditionFailed", "At least one of the pre-conditions you specified did not hold", http.StatusPreconditionFailed}
+	errRequestTimeTooSkewed              = &s3Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large", http.StatusForbidden}
+	errSignatureDoesNotMatch             = &s3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided", http.StatusForbidden}
+	errUnsupportedSignature              = &s3Error{"AccessDenied", "Only AWS4-HMAC-SHA256 signatures with signed or unsigned payloads are supported", http.StatusBadRequest}
+	errXAmzContentSHA256Mismatch         = &s3Error{"XAmzContentSHA256Mismatch", "The provided x-amz-content-sha256 header does not match what was computed", http.StatusBadRequest}
+)
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+func toS3Error(err error) *s3Error {
+	var s3Err *s3Error
+
+	switch {
+	case stderr.As(err, &s3Err):
+		return s3Err
+	case stderr.Is(err, errors.ErrObjectNotFound), stderr.Is(err, fs.ErrNotExist):
+		return errNoSuchKey
+	case stderr.Is(err, errors.ErrObjectAlreadyExists):
+		return errPreconditionFailed
+	case stderr.Is(err, errContentSHA256Mismatch):
+		return errXAmzContentSHA256Mismatch
+	case stderr.Is(err, stderr.ErrUnsupported):
+		return errNotImplemented
+	default:
+		return &s3Error{errInternalError.Code, err.Error(), errInternalError.StatusCode}
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var s3Err = toS3Error(err)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(s3Err.StatusCode)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(errorResponse{
+		Code:     s3Err.Code,
+		Message:  s3Err.Message,
+		Resource: r.URL.Path,
+	})
+}
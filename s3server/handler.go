@@ -0,0 +1,593 @@
+package s3server
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	stderr "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/errors"
+	"github.com/agnosticeng/objstr/types"
+)
+
+type bucket struct {
+	name string
+	u    *url.URL
+	root string
+}
+
+// Handler serves a subset of the S3 REST API, using path-style addressing,
+// on top of the buckets mapped to ObjectStore prefixes.
+type Handler struct {
+	conf    HandlerConfig
+	store   *objstr.ObjectStore
+	buckets map[string]*bucket
+	staging *bucket
+}
+
+func NewHandler(store *objstr.ObjectStore, conf HandlerConfig) (*Handler, error) {
+	if len(conf.Buckets) == 0 {
+		return nil, fmt.Errorf("at least one bucket must be specified")
+	}
+
+	if len(conf.Credentials) == 0 && !conf.Anonymous {
+		return nil, fmt.Errorf("credentials must be specified unless anonymous access is enabled")
+	}
+
+	if len(conf.MultipartStagingURL) == 0 {
+		conf.MultipartStagingURL = "file://" + filepath.Join(os.TempDir(), "objstr-s3server")
+	}
+
+	var buckets = make(map[string]*bucket)
+
+	for name, rawUrl := range conf.Buckets {
+		b, err := newBucket(name, rawUrl)
+
+		if err != nil {
+			return nil, err
+		}
+
+		buckets[name] = b
+	}
+
+	staging, err := newBucket("", conf.MultipartStagingURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		conf:    conf,
+		store:   store,
+		buckets: buckets,
+		staging: staging,
+	}, nil
+}
+
+func newBucket(name string, rawUrl string) (*bucket, error) {
+	u, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s: %w", rawUrl, err)
+	}
+
+	var root = u.Path
+
+	if !strings.HasSuffix(root, "/") {
+		root = root + "/"
+	}
+
+	return &bucket{name: name, u: u, root: root}, nil
+}
+
+func (b *bucket) objectURL(key string) *url.URL {
+	var u = *b.u
+	u.Path = b.root + key
+	return &u
+}
+
+func (b *bucket) key(u *url.URL) (string, bool) {
+	return strings.CutPrefix(u.Path, b.root)
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.authenticate(r); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var (
+		q                  = r.URL.Query()
+		bucketName, key, _ = strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	)
+
+	if len(bucketName) == 0 {
+		if r.Method != http.MethodGet {
+			writeError(w, r, errMethodNotAllowed)
+			return
+		}
+
+		h.listBuckets(w, r)
+		return
+	}
+
+	b, found := h.buckets[bucketName]
+
+	if !found {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+
+	if len(key) == 0 {
+		switch {
+		case r.Method == http.MethodHead, r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && q.Has("location"):
+			writeXML(w, locationConstraint{Xmlns: s3Namespace})
+		case r.Method == http.MethodGet && q.Get("list-type") == "2":
+			h.listObjectsV2(w, r, b)
+		case r.Method == http.MethodGet:
+			h.listObjectsV1(w, r, b)
+		case r.Method == http.MethodPost && q.Has("delete"):
+			h.deleteObjects(w, r, b)
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+
+		return
+	}
+
+	if !validKey(key) {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+
+	switch {
+	case (r.Method == http.MethodGet) && q.Has("uploadId"):
+		h.listParts(w, r, b, key)
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		h.getObject(w, r, b, key)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		h.uploadPart(w, r, b, key)
+	case r.Method == http.MethodPut && len(r.Header.Get("X-Amz-Copy-Source")) > 0:
+		h.copyObject(w, r, b, key)
+	case r.Method == http.MethodPut:
+		h.putObject(w, r, b, key)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		h.createMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		h.completeMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		h.abortMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodDelete:
+		h.deleteObject(w, r, b, key)
+	default:
+		writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+func (h *Handler) listBuckets(w http.ResponseWriter, r *http.Request) {
+	var res = listAllMyBucketsResult{Xmlns: s3Namespace}
+
+	for name := range h.buckets {
+		res.Buckets = append(res.Buckets, bucketEntry{Name: name})
+	}
+
+	sort.Slice(res.Buckets, func(i, j int) bool {
+		return res.Buckets[i].Name < res.Buckets[j].Name
+	})
+
+	writeXML(w, res)
+}
+
+func (h *Handler) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	if !fs.ValidPath(key) {
+		writeError(w, r, errNoSuchKey)
+		return
+	}
+
+	f, err := objstr.FS(r.Context(), h.store, b.u).Open(key)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if info.IsDir() {
+		writeError(w, r, errNoSuchKey)
+		return
+	}
+
+	if md, ok := info.Sys().(*types.ObjectMetadata); ok && len(md.ETag) > 0 {
+		w.Header().Set("ETag", quoteETag(md.ETag))
+	}
+
+	var contentType = mime.TypeByExtension(path.Ext(key))
+
+	if len(contentType) == 0 {
+		contentType = "binary/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f.(io.ReadSeeker))
+}
+
+func (h *Handler) putObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	etag, err := h.writeObject(r, b.objectURL(key), r.Body, writeOptions(r)...)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(etag))
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeOptions maps the Content-Type, x-amz-meta-* and If-None-Match: *
+// headers of a PutObject request
+func writeOptions(r *http.Request) []types.WriteOption {
+	var (
+		opts []types.WriteOption
+		md   = make(map[string]string)
+	)
+
+	if contentType := r.Header.Get("Content-Type"); len(contentType) > 0 {
+		opts = append(opts, types.WithContentType(contentType))
+	}
+
+	for k, v := range r.Header {
+		if name, found := strings.CutPrefix(strings.ToLower(k), "x-amz-meta-"); found && len(v) > 0 {
+			md[name] = v[0]
+		}
+	}
+
+	if len(md) > 0 {
+		opts = append(opts, types.WithMetadata(md))
+	}
+
+	if r.Header.Get("If-None-Match") == "*" {
+		opts = append(opts, types.WithIfAbsent())
+	}
+
+	return opts
+}
+
+// writeObject streams the body to u and returns the hex encoded MD5 of the
+// content; the destination writer is aborted when the body does not match
+// its Content-MD5 header
+func (h *Handler) writeObject(r *http.Request, u *url.URL, body io.Reader, opts ...types.WriteOption) (string, error) {
+	writer, err := h.store.Writer(r.Context(), u, opts...)
+
+	if err != nil {
+		return "", err
+	}
+
+	var hash = md5.New()
+
+	if _, err := io.Copy(io.MultiWriter(writer, hash), body); err != nil {
+		writer.Abort()
+		return "", err
+	}
+
+	var sum = hash.Sum(nil)
+
+	if contentMD5 := r.Header.Get("Content-MD5"); len(contentMD5) > 0 && contentMD5 != base64.StdEncoding.EncodeToString(sum) {
+		writer.Abort()
+		return "", errBadDigest
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum), nil
+}
+
+func (h *Handler) copyObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	src, err := h.copySource(r)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var dst = b.objectURL(key)
+
+	if err := h.store.Copy(r.Context(), src, dst); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var res = copyObjectResult{
+		Xmlns:        s3Namespace,
+		LastModified: s3Time(time.Now()),
+	}
+
+	if md, err := h.store.ReadMetadata(r.Context(), dst); err == nil {
+		res.ETag = quoteETag(md.ETag)
+
+		if !md.ModificationDate.IsZero() {
+			res.LastModified = s3Time(md.ModificationDate)
+		}
+	}
+
+	writeXML(w, res)
+}
+
+func (h *Handler) copySource(r *http.Request) (*url.URL, error) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+
+	if err != nil {
+		return nil, errInvalidArgument
+	}
+
+	source, _, _ = strings.Cut(source, "?")
+
+	var bucketName, key, _ = strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+	b, found := h.buckets[bucketName]
+
+	if !found {
+		return nil, errNoSuchBucket
+	}
+
+	if !validKey(key) {
+		return nil, errInvalidArgument
+	}
+
+	return b.objectURL(key), nil
+}
+
+func (h *Handler) deleteObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	if err := h.store.Delete(r.Context(), b.objectURL(key)); err != nil && !isNotFound(err) {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteObjects(w http.ResponseWriter, r *http.Request, b *bucket) {
+	var req deleteRequest
+
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	var res = deleteResult{Xmlns: s3Namespace}
+
+	for _, obj := range req.Objects {
+		var err error
+
+		if !validKey(obj.Key) {
+			err = errInvalidArgument
+		} else if err = h.store.Delete(r.Context(), b.objectURL(obj.Key)); isNotFound(err) {
+			err = nil
+		}
+
+		if err != nil {
+			var s3Err = toS3Error(err)
+			res.Errors = append(res.Errors, deleteError{Key: obj.Key, Code: s3Err.Code, Message: s3Err.Message})
+			continue
+		}
+
+		if !req.Quiet {
+			res.Deleted = append(res.Deleted, deletedObject{Key: obj.Key})
+		}
+	}
+
+	writeXML(w, res)
+}
+
+func (h *Handler) listObjectsV1(w http.ResponseWriter, r *http.Request, b *bucket) {
+	var q = r.URL.Query()
+
+	maxKeys, err := parseMaxKeys(q.Get("max-keys"))
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var (
+		marker = q.Get("marker")
+		res    = listBucketResult{
+			Xmlns:     s3Namespace,
+			Name:      b.name,
+			Prefix:    q.Get("prefix"),
+			Delimiter: q.Get("delimiter"),
+			MaxKeys:   maxKeys,
+			Marker:    &marker,
+		}
+	)
+
+	lastKey, err := h.fillListing(r, b, &res, marker)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if res.IsTruncated {
+		res.NextMarker = lastKey
+	}
+
+	writeXML(w, res)
+}
+
+func (h *Handler) listObjectsV2(w http.ResponseWriter, r *http.Request, b *bucket) {
+	var q = r.URL.Query()
+
+	maxKeys, err := parseMaxKeys(q.Get("max-keys"))
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var (
+		after = q.Get("start-after")
+		res   = listBucketResult{
+			Xmlns:             s3Namespace,
+			Name:              b.name,
+			Prefix:            q.Get("prefix"),
+			Delimiter:         q.Get("delimiter"),
+			MaxKeys:           maxKeys,
+			StartAfter:        q.Get("start-after"),
+			ContinuationToken: q.Get("continuation-token"),
+		}
+	)
+
+	if len(res.ContinuationToken) > 0 {
+		token, err := base64.RawURLEncoding.DecodeString(res.ContinuationToken)
+
+		if err != nil {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+
+		after = max(after, string(token))
+	}
+
+	lastKey, err := h.fillListing(r, b, &res, after)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var keyCount = len(res.Contents) + len(res.CommonPrefixes)
+	res.KeyCount = &keyCount
+
+	if res.IsTruncated {
+		res.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+	}
+
+	writeXML(w, res)
+}
+
+// fillListing lists the keys of the bucket that sort after the given key
+// and returns the last key added to the result
+func (h *Handler) fillListing(r *http.Request, b *bucket, res *listBucketResult, after string) (string, error) {
+	var opts []types.ListOption
+
+	if len(res.Delimiter) > 0 {
+		opts = append(opts, types.WithDelimiter(res.Delimiter))
+	}
+
+	objects, err := h.store.ListPrefix(r.Context(), b.objectURL(res.Prefix), opts...)
+
+	if err != nil {
+		return "", err
+	}
+
+	var keyed = make(map[string]*types.Object, len(objects))
+
+	for _, obj := range objects {
+		if key, found := b.key(obj.URL); found && key > after {
+			keyed[key] = obj
+		}
+	}
+
+	var keys = make([]string, 0, len(keyed))
+
+	for key := range keyed {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if len(keys) > res.MaxKeys {
+		keys = keys[:res.MaxKeys]
+		res.IsTruncated = true
+	}
+
+	for _, key := range keys {
+		var obj = keyed[key]
+
+		if obj.IsPrefix {
+			res.CommonPrefixes = append(res.CommonPrefixes, commonPrefix{Prefix: key})
+			continue
+		}
+
+		res.Contents = append(res.Contents, contentEntry{
+			Key:          key,
+			LastModified: s3Time(obj.Metadata.ModificationDate),
+			ETag:         quoteETag(obj.Metadata.ETag),
+			Size:         obj.Metadata.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	return keys[len(keys)-1], nil
+}
+
+func parseMaxKeys(s string) (int, error) {
+	if len(s) == 0 {
+		return maxKeysDefault, nil
+	}
+
+	n, err := strconv.Atoi(s)
+
+	if err != nil || n < 0 {
+		return 0, errInvalidArgument
+	}
+
+	return min(n, maxKeysDefault), nil
+}
+
+func quoteETag(etag string) string {
+	if len(etag) == 0 || strings.HasPrefix(etag, "\"") {
+		return etag
+	}
+
+	return "\"" + etag + "\""
+}
+
+func isNotFound(err error) bool {
+	return stderr.Is(err, errors.ErrObjectNotFound) || stderr.Is(err, fs.ErrNotExist)
+}
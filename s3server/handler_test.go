@@ -0,0 +1,285 @@
+package s3server_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agnosticeng/objstr"
+	"github.com/agnosticeng/objstr/s3server"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+const (
+	testAccessKeyId     = "AKIDTEST"
+	testSecretAccessKey = "secret"
+	testRegion          = "us-east-1"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	store, err := objstr.NewObjectStore(context.Background(), objstr.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { store.Close() })
+
+	handler, err := s3server.NewHandler(store, s3server.HandlerConfig{
+		Buckets:             map[string]string{"b": "memory://s3server/b/"},
+		Credentials:         map[string]string{testAccessKeyId: testSecretAccessKey},
+		MultipartStagingURL: "memory://s3server/staging/",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, secret string) *s3.S3 {
+	t.Helper()
+
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(testAccessKeyId, secret, ""),
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String(testRegion),
+		S3ForcePathStyle: aws.Bool(true),
+		HTTPClient:       server.Client(),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s3.New(sess)
+}
+
+func errorCode(err error) string {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code()
+	}
+
+	return ""
+}
+
+func getObject(t *testing.T, client *s3.S3, key string) []byte {
+	t.Helper()
+
+	output, err := client.GetObject(&s3.GetObjectInput{Bucket: aws.String("b"), Key: aws.String(key)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer output.Body.Close()
+
+	content, err := io.ReadAll(output.Body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content
+}
+
+func TestSignedRequests(t *testing.T) {
+	var (
+		server = newTestServer(t)
+		client = newTestClient(t, server, testSecretAccessKey)
+	)
+
+	_, err := client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("b"),
+		Key:    aws.String("dir/a.txt"),
+		Body:   strings.NewReader("hello"),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content := getObject(t, client, "dir/a.txt"); string(content) != "hello" {
+		t.Fatalf("unexpected content: %q", content)
+	}
+
+	_, err = newTestClient(t, server, "wrong").HeadObject(&s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("dir/a.txt")})
+
+	if err == nil {
+		t.Fatal("a request signed with the wrong secret must fail")
+	}
+
+	resp, err := server.Client().Get(server.URL + "/b/dir/a.txt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unsigned request: unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestPresignedRequests(t *testing.T) {
+	var (
+		server = newTestServer(t)
+		client = newTestClient(t, server, testSecretAccessKey)
+	)
+
+	_, err := client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String("b"),
+		Key:    aws.String("a.txt"),
+		Body:   strings.NewReader("presigned"),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := client.GetObjectRequest(&s3.GetObjectInput{Bucket: aws.String("b"), Key: aws.String("a.txt")})
+
+	presignedURL, err := req.Presign(15 * time.Minute)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		url    string
+		status int
+	}{
+		{presignedURL, http.StatusOK},
+		{strings.Replace(presignedURL, "a.txt", "b.txt", 1), http.StatusForbidden},
+	} {
+		resp, err := server.Client().Get(tc.url)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: unexpected status %d: %s", tc.url, resp.StatusCode, content)
+		}
+
+		if tc.status == http.StatusOK && string(content) != "presigned" {
+			t.Fatalf("unexpected content: %q", content)
+		}
+	}
+}
+
+func TestPayloadVerification(t *testing.T) {
+	var (
+		server = newTestServer(t)
+		client = newTestClient(t, server, testSecretAccessKey)
+	)
+
+	_, err := client.PutObject(&s3.PutObjectInput{
+		Bucket:     aws.String("b"),
+		Key:        aws.String("md5.txt"),
+		Body:       strings.NewReader("content"),
+		ContentMD5: aws.String("1B2M2Y8AsgTpgAmY7PhCfg=="),
+	})
+
+	if code := errorCode(err); code != "BadDigest" {
+		t.Fatalf("Content-MD5 mismatch: unexpected error %v", err)
+	}
+
+	// the body is signed then replaced and sent chunked, without length
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/b/sha.txt", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signer = v4.NewSigner(credentials.NewStaticCredentials(testAccessKeyId, testSecretAccessKey, ""))
+
+	if _, err := signer.Sign(req, strings.NewReader("signed"), "s3", testRegion, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	req.Body = io.NopCloser(strings.NewReader("tampered"))
+	req.ContentLength = -1
+
+	resp, err := server.Client().Do(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(content), "XAmzContentSHA256Mismatch") {
+		t.Fatalf("x-amz-content-sha256 mismatch: unexpected response %d: %s", resp.StatusCode, content)
+	}
+
+	for _, key := range []string{"md5.txt", "sha.txt"} {
+		_, err := client.HeadObject(&s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String(key)})
+
+		if err == nil {
+			t.Fatalf("%s must not have been written", key)
+		}
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	var (
+		server  = newTestServer(t)
+		client  = newTestClient(t, server, testSecretAccessKey)
+		content = make([]byte, 11*1024*1024)
+	)
+
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+
+	var uploader = s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+		u.PartSize = 5 * 1024 * 1024
+	})
+
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String("b"),
+		Key:    aws.String("large.bin"),
+		Body:   bytes.NewReader(content),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := getObject(t, client, "large.bin"); !bytes.Equal(res, content) {
+		t.Fatalf("unexpected content: %d bytes instead of %d", len(res), len(content))
+	}
+
+	output, err := client.ListMultipartUploads(&s3.ListMultipartUploadsInput{Bucket: aws.String("b")})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Uploads) != 0 {
+		t.Fatalf("%d uploads left after completion", len(output.Uploads))
+	}
+}
@@ -0,0 +1,405 @@
+package s3server
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/agnosticeng/objstr/utils"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-multierror"
+)
+
+const maxPartNumber = 10000
+
+// Multipart uploads are staged as objects under the staging prefix:
+// <uploadId>/upload.json describes the destination and every part is
+// stored as <uploadId>/part-<number>-<md5>.
+
+type multipartUpload struct {
+	Bucket    string
+	Key       string
+	Initiated time.Time
+}
+
+type stagedPart struct {
+	number int
+	etag   string
+	obj    *types.Object
+}
+
+func (h *Handler) createMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	var uploadId = uuid.Must(uuid.NewV7()).String()
+
+	content, err := json.Marshal(multipartUpload{
+		Bucket:    b.name,
+		Key:       key,
+		Initiated: time.Now(),
+	})
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := utils.CreateObject(r.Context(), h.store, h.staging.objectURL(uploadId+"/upload.json"), content); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeXML(w, initiateMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Bucket:   b.name,
+		Key:      key,
+		UploadId: uploadId,
+	})
+}
+
+func (h *Handler) checkUpload(r *http.Request, b *bucket, key string) (string, error) {
+	var uploadId = r.URL.Query().Get("uploadId")
+
+	if _, err := uuid.Parse(uploadId); err != nil {
+		return "", errNoSuchUpload
+	}
+
+	content, err := utils.ReadObject(r.Context(), h.store, h.staging.objectURL(uploadId+"/upload.json"))
+
+	if isNotFound(err) {
+		return "", errNoSuchUpload
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var upload multipartUpload
+
+	if err := json.Unmarshal(content, &upload); err != nil {
+		return "", err
+	}
+
+	if upload.Bucket != b.name || upload.Key != key {
+		return "", errNoSuchUpload
+	}
+
+	return uploadId, nil
+}
+
+func (h *Handler) stagedParts(r *http.Request, uploadId string) ([]stagedPart, error) {
+	objects, err := h.store.ListPrefix(r.Context(), h.staging.objectURL(uploadId+"/part-"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var res []stagedPart
+
+	for _, obj := range objects {
+		key, _ := h.staging.key(obj.URL)
+		rawNumber, etag, found := strings.Cut(strings.TrimPrefix(key, uploadId+"/part-"), "-")
+
+		if !found {
+			continue
+		}
+
+		number, err := strconv.Atoi(rawNumber)
+
+		if err != nil {
+			continue
+		}
+
+		res = append(res, stagedPart{number: number, etag: etag, obj: obj})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].number < res[j].number
+	})
+
+	return res, nil
+}
+
+func (h *Handler) uploadPart(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	if len(r.Header.Get("X-Amz-Copy-Source")) > 0 {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+
+	partNumber, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+
+	uploadId, err := h.checkUpload(r, b, key)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var tmpURL = h.staging.objectURL(uploadId + "/tmp-" + uuid.Must(uuid.NewV7()).String())
+
+	etag, err := h.writeObject(r, tmpURL, r.Body)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	parts, err := h.stagedParts(r, uploadId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	for _, part := range parts {
+		if part.number != partNumber {
+			continue
+		}
+
+		if err := h.store.Delete(r.Context(), part.obj.URL); err != nil && !isNotFound(err) {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	var partURL = h.staging.objectURL(fmt.Sprintf("%s/part-%05d-%s", uploadId, partNumber, etag))
+
+	if err := h.store.Move(r.Context(), tmpURL, partURL); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", quoteETag(etag))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) listParts(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	var q = r.URL.Query()
+
+	uploadId, err := h.checkUpload(r, b, key)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var (
+		marker   int
+		maxParts = maxPartsDefault
+	)
+
+	if v := q.Get("part-number-marker"); len(v) > 0 {
+		if marker, err = strconv.Atoi(v); err != nil {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+	}
+
+	if v := q.Get("max-parts"); len(v) > 0 {
+		if maxParts, err = strconv.Atoi(v); err != nil || maxParts < 0 {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+
+		maxParts = min(maxParts, maxPartsDefault)
+	}
+
+	parts, err := h.stagedParts(r, uploadId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var res = listPartsResult{
+		Xmlns:            s3Namespace,
+		Bucket:           b.name,
+		Key:              key,
+		UploadId:         uploadId,
+		PartNumberMarker: marker,
+		MaxParts:         maxParts,
+	}
+
+	for _, part := range parts {
+		if part.number <= marker {
+			continue
+		}
+
+		if len(res.Parts) == maxParts {
+			res.IsTruncated = true
+			break
+		}
+
+		res.Parts = append(res.Parts, partEntry{
+			PartNumber:   part.number,
+			LastModified: s3Time(part.obj.Metadata.ModificationDate),
+			ETag:         quoteETag(part.etag),
+			Size:         part.obj.Metadata.Size,
+		})
+
+		res.NextPartNumberMarker = part.number
+	}
+
+	writeXML(w, res)
+}
+
+func (h *Handler) completeMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	uploadId, err := h.checkUpload(r, b, key)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req completeMultipartUpload
+
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	staged, err := h.stagedParts(r, uploadId)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var (
+		stagedIdx = make(map[int]stagedPart)
+		selected  []stagedPart
+		md5s      bytes.Buffer
+	)
+
+	for _, part := range staged {
+		stagedIdx[part.number] = part
+	}
+
+	for i, part := range req.Parts {
+		if i > 0 && part.PartNumber <= req.Parts[i-1].PartNumber {
+			writeError(w, r, errInvalidPartOrder)
+			return
+		}
+
+		s, found := stagedIdx[part.PartNumber]
+
+		if !found || s.etag != strings.Trim(part.ETag, "\"") {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+
+		sum, err := hex.DecodeString(s.etag)
+
+		if err != nil {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+
+		md5s.Write(sum)
+		selected = append(selected, s)
+	}
+
+	if err := h.concatParts(r, b.objectURL(key), selected); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var sum = md5.Sum(md5s.Bytes())
+
+	if err := h.deleteUpload(r, uploadId); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeXML(w, completeMultipartUploadResult{
+		Xmlns:    s3Namespace,
+		Location: r.URL.Path,
+		Bucket:   b.name,
+		Key:      key,
+		ETag:     quoteETag(fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(selected))),
+	})
+}
+
+func (h *Handler) concatParts(r *http.Request, dst *url.URL, parts []stagedPart) error {
+	var opts []types.WriteOption
+
+	if r.Header.Get("If-None-Match") == "*" {
+		opts = append(opts, types.WithIfAbsent())
+	}
+
+	writer, err := h.store.Writer(r.Context(), dst, opts...)
+
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := h.copyPart(r, writer, part); err != nil {
+			writer.Abort()
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func (h *Handler) copyPart(r *http.Request, w io.Writer, part stagedPart) error {
+	reader, err := h.store.Reader(r.Context(), part.obj.URL)
+
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+func (h *Handler) abortMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) {
+	uploadId, err := h.checkUpload(r, b, key)
+
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.deleteUpload(r, uploadId); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteUpload(r *http.Request, uploadId string) error {
+	objects, err := h.store.ListPrefix(r.Context(), h.staging.objectURL(uploadId+"/"))
+
+	if err != nil {
+		return err
+	}
+
+	var res *multierror.Error
+
+	for _, obj := range objects {
+		if err := h.store.Delete(r.Context(), obj.URL); err != nil && !isNotFound(err) {
+			res = multierror.Append(res, err)
+		}
+	}
+
+	return res.ErrorOrNil()
+}
@@ -0,0 +1,287 @@
+package objstr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/agnosticeng/objstr/types"
+	"github.com/sourcegraph/conc/iter"
+)
+
+type SyncAction int
+
+const (
+	// SyncActionCopy copies an object missing from the destination.
+	SyncActionCopy SyncAction = iota
+	// SyncActionUpdate overwrites a destination object that differs.
+	SyncActionUpdate
+	// SyncActionDelete deletes a destination object missing from the source.
+	SyncActionDelete
+)
+
+func (a SyncAction) String() string {
+	switch a {
+	case SyncActionCopy:
+		return "COPY"
+	case SyncActionUpdate:
+		return "UPDATE"
+	case SyncActionDelete:
+		return "DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (a SyncAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *SyncAction) UnmarshalText(text []byte) error {
+	for _, action := range []SyncAction{SyncActionCopy, SyncActionUpdate, SyncActionDelete} {
+		if action.String() == string(text) {
+			*a = action
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown sync action: %s", string(text))
+}
+
+type SyncOptions struct {
+	// MaxConcurrentRequests bounds the number of objects synced at once,
+	// DefaultMaxConcurrentRequests is used when zero.
+	MaxConcurrentRequests int
+	// CompareMode decides which objects present on both sides are
+	// overwritten, it defaults to CompareSize.
+	CompareMode CompareMode
+	CopyOptions []types.CopyOption
+	// Delete enables the deletion of destination objects missing from the
+	// source.
+	Delete bool
+	// MaxDelete and MaxDeletePercent abort the sync before any operation is
+	// executed when the plan deletes more objects, or a larger share of the
+	// destination objects; zero disables the limit.
+	MaxDelete        int
+	MaxDeletePercent float64
+	// DryRun reports the operations through OnResult without executing them.
+	DryRun bool
+	// OnResult is called once per action taken, possibly concurrently.
+	OnResult func(SyncResult)
+}
+
+type SyncResult struct {
+	Action SyncAction
+	// Src is nil for deletions.
+	Src *url.URL
+	Dst *url.URL
+	Err error
+}
+
+type SyncOperation struct {
+	Action SyncAction `json:"action"`
+	Src    string     `json:"src,omitempty"`
+	Dst    string     `json:"dst"`
+}
+
+// SyncPlan lists the operations a sync would execute. It can be serialized
+// for review and executed later with ApplySyncPlan.
+// SyncPlan records the delete limits it was planned with, so that applying
+// a saved plan is guarded the same way as the sync that produced it.
+type SyncPlan struct {
+	Source             string          `json:"source"`
+	Destination        string          `json:"destination"`
+	CreatedAt          time.Time       `json:"created_at"`
+	DestinationObjects int             `json:"destination_objects"`
+	MaxDelete          int             `json:"max_delete,omitempty"`
+	MaxDeletePercent   float64         `json:"max_delete_percent,omitempty"`
+	Operations         []SyncOperation `json:"operations"`
+}
+
+func (plan *SyncPlan) deletes() int {
+	var res int
+
+	for _, op := range plan.Operations {
+		if op.Action == SyncActionDelete {
+			res++
+		}
+	}
+
+	return res
+}
+
+func (plan *SyncPlan) checkDeleteLimits(maxDelete int, maxDeletePercent float64) error {
+	var deletes = plan.deletes()
+
+	if maxDelete > 0 && deletes > maxDelete {
+		return fmt.Errorf("sync would delete %d objects, more than the limit of %d", deletes, maxDelete)
+	}
+
+	if maxDeletePercent > 0 && plan.DestinationObjects > 0 {
+		var percent = float64(deletes) * 100 / float64(plan.DestinationObjects)
+
+		if percent > maxDeletePercent {
+			return fmt.Errorf(
+				"sync would delete %d of %d destination objects (%.1f%%), more than the limit of %.1f%%",
+				deletes, plan.DestinationObjects, percent, maxDeletePercent,
+			)
+		}
+	}
+
+	return nil
+}
+
+// PlanSync computes the operations that make dstPrefix a copy of srcPrefix:
+// missing objects are copied, objects that differ according to the compare
+// mode are overwritten and, when Delete is set, objects only present under
+// dstPrefix are deleted. Nothing is modified.
+func (os *ObjectStore) PlanSync(ctx context.Context, srcPrefix *url.URL, dstPrefix *url.URL, opts *SyncOptions) (*SyncPlan, error) {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+
+	srcObjs, err := os.ListPrefix(ctx, srcPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dstObjs, err := os.ListPrefix(ctx, dstPrefix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs = types.Associate(
+		srcPrefix,
+		srcObjs,
+		dstPrefix,
+		dstObjs,
+	)
+
+	type planned struct {
+		op  *SyncOperation
+		err error
+	}
+
+	var mapper = iter.Mapper[types.ObjectPair, planned]{
+		MaxGoroutines: maxConcurrentRequests(opts.MaxConcurrentRequests),
+	}
+
+	var results = mapper.Map(pairs, func(pair *types.ObjectPair) planned {
+		switch {
+		case pair.Left == nil:
+			if !opts.Delete {
+				return planned{}
+			}
+
+			return planned{op: &SyncOperation{Action: SyncActionDelete, Dst: pair.Right.URL.String()}}
+
+		case pair.Right == nil:
+			dst, err := types.GenerateDstURL(dstPrefix, srcPrefix, pair.Left.URL)
+
+			if err != nil {
+				return planned{err: err}
+			}
+
+			return planned{op: &SyncOperation{Action: SyncActionCopy, Src: pair.Left.URL.String(), Dst: dst.String()}}
+
+		default:
+			_, differs, err := os.compare(ctx, opts.CompareMode, pair.Left, pair.Right)
+
+			if err != nil || !differs {
+				return planned{err: err}
+			}
+
+			return planned{op: &SyncOperation{Action: SyncActionUpdate, Src: pair.Left.URL.String(), Dst: pair.Right.URL.String()}}
+		}
+	})
+
+	var (
+		plan = SyncPlan{
+			Source:             srcPrefix.String(),
+			Destination:        dstPrefix.String(),
+			CreatedAt:          time.Now().UTC(),
+			DestinationObjects: len(dstObjs),
+			MaxDelete:          opts.MaxDelete,
+			MaxDeletePercent:   opts.MaxDeletePercent,
+		}
+		errs []error
+	)
+
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+		}
+
+		if res.op != nil {
+			plan.Operations = append(plan.Operations, *res.op)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &plan, nil
+}
+
+// ApplySyncPlan checks the delete limits recorded in the plan and those of
+// the options against the plan then executes its operations.
+func (os *ObjectStore) ApplySyncPlan(ctx context.Context, plan *SyncPlan, opts *SyncOptions) error {
+	if opts == nil {
+		opts = &SyncOptions{}
+	}
+
+	if err := plan.checkDeleteLimits(plan.MaxDelete, plan.MaxDeletePercent); err != nil {
+		return err
+	}
+
+	if err := plan.checkDeleteLimits(opts.MaxDelete, opts.MaxDeletePercent); err != nil {
+		return err
+	}
+
+	var mapper = iter.Mapper[SyncOperation, error]{
+		MaxGoroutines: maxConcurrentRequests(opts.MaxConcurrentRequests),
+	}
+
+	var errs = mapper.Map(plan.Operations, func(op *SyncOperation) error {
+		var res = SyncResult{Action: op.Action}
+
+		res.Dst, res.Err = url.Parse(op.Dst)
+
+		if res.Err == nil && op.Action != SyncActionDelete {
+			res.Src, res.Err = url.Parse(op.Src)
+		}
+
+		if res.Err == nil && !opts.DryRun {
+			switch op.Action {
+			case SyncActionDelete:
+				res.Err = os.Delete(ctx, res.Dst)
+			default:
+				res.Err = os.Copy(ctx, res.Src, res.Dst, opts.CopyOptions...)
+			}
+		}
+
+		if opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+
+		return res.Err
+	})
+
+	return errors.Join(errs...)
+}
+
+// Sync plans then applies a sync, see PlanSync and ApplySyncPlan.
+func (os *ObjectStore) Sync(ctx context.Context, srcPrefix *url.URL, dstPrefix *url.URL, opts *SyncOptions) error {
+	plan, err := os.PlanSync(ctx, srcPrefix, dstPrefix, opts)
+
+	if err != nil {
+		return err
+	}
+
+	return os.ApplySyncPlan(ctx, plan, opts)
+}
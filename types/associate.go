@@ -0,0 +1,61 @@
+package types
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// ObjectPair holds the objects found at the same path relative to the left
+// and right prefixes; either side is nil when the object is missing there.
+type ObjectPair struct {
+	Path  string
+	Left  *Object
+	Right *Object
+}
+
+func Associate(
+	leftPrefix *url.URL,
+	leftObjects []*Object,
+	rightPrefix *url.URL,
+	rightObjects []*Object,
+) []ObjectPair {
+	var (
+		res            []ObjectPair
+		leftObjectsIdx = lo.Associate(leftObjects, func(o *Object) (string, *Object) {
+			return keyedObject(leftPrefix, o)
+		})
+		rightObjectsIdx = lo.Associate(rightObjects, func(o *Object) (string, *Object) {
+			return keyedObject(rightPrefix, o)
+		})
+	)
+
+	for _, leftObject := range leftObjects {
+		var key, _ = keyedObject(leftPrefix, leftObject)
+
+		res = append(res, ObjectPair{
+			Path:  key,
+			Left:  leftObject,
+			Right: rightObjectsIdx[key],
+		})
+	}
+
+	for _, rightObject := range rightObjects {
+		var key, _ = keyedObject(rightPrefix, rightObject)
+
+		if leftObjectsIdx[key] == nil {
+			res = append(res, ObjectPair{
+				Path:  key,
+				Left:  nil,
+				Right: rightObject,
+			})
+		}
+	}
+
+	return res
+}
+
+func keyedObject(prefix *url.URL, obj *Object) (string, *Object) {
+	return strings.TrimPrefix(obj.URL.Path, prefix.Path), obj
+}
@@ -14,4 +14,5 @@ type ObjectMetadata struct {
 type Object struct {
 	URL      *url.URL
 	Metadata *ObjectMetadata
+	IsPrefix bool
 }
@@ -1,7 +1,10 @@
 package types
 
+import "time"
+
 type ListOptions struct {
 	StartAfter string
+	Delimiter  string
 }
 
 type ListOption func(*ListOptions)
@@ -12,6 +15,12 @@ func WithStartAfter(s string) ListOption {
 	}
 }
 
+func WithDelimiter(s string) ListOption {
+	return func(opts *ListOptions) {
+		opts.Delimiter = s
+	}
+}
+
 func NewListOptions(opts ...ListOption) *ListOptions {
 	var res ListOptions
 
@@ -21,3 +30,86 @@ func NewListOptions(opts ...ListOption) *ListOptions {
 
 	return &res
 }
+
+type CopyOptions struct {
+	ResumeStateDir string
+	// Verify is the compare mode (size, size-mtime, etag or hash) used to
+	// check the destination against the source once copied.
+	Verify string
+}
+
+type CopyOption func(*CopyOptions)
+
+func WithResumeStateDir(dir string) CopyOption {
+	return func(opts *CopyOptions) {
+		opts.ResumeStateDir = dir
+	}
+}
+
+func WithVerify(mode string) CopyOption {
+	return func(opts *CopyOptions) {
+		opts.Verify = mode
+	}
+}
+
+func NewCopyOptions(opts ...CopyOption) *CopyOptions {
+	var res CopyOptions
+
+	for _, opt := range opts {
+		opt(&res)
+	}
+
+	return &res
+}
+
+type WriteOptions struct {
+	ContentType string
+	Metadata    map[string]string
+	// IfAbsent makes Close fail with errors.ErrObjectAlreadyExists when the
+	// object already exists.
+	IfAbsent bool
+	// TTL makes the object expire, on backends that support it.
+	TTL time.Duration
+}
+
+type WriteOption func(*WriteOptions)
+
+func WithContentType(contentType string) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.ContentType = contentType
+	}
+}
+
+func WithMetadata(md map[string]string) WriteOption {
+	return func(opts *WriteOptions) {
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]string)
+		}
+
+		for k, v := range md {
+			opts.Metadata[k] = v
+		}
+	}
+}
+
+func WithIfAbsent() WriteOption {
+	return func(opts *WriteOptions) {
+		opts.IfAbsent = true
+	}
+}
+
+func WithTTL(ttl time.Duration) WriteOption {
+	return func(opts *WriteOptions) {
+		opts.TTL = ttl
+	}
+}
+
+func NewWriteOptions(opts ...WriteOption) *WriteOptions {
+	var res WriteOptions
+
+	for _, opt := range opts {
+		opt(&res)
+	}
+
+	return &res
+}
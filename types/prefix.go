@@ -0,0 +1,56 @@
+package types
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GroupByDelimiter replaces every object whose path contains the delimiter
+// after the given prefix by a single prefix object, the way S3 builds
+// common prefixes. Input order is preserved.
+func GroupByDelimiter(prefix string, objects []*Object, delimiter string) []*Object {
+	if len(delimiter) == 0 {
+		return objects
+	}
+
+	var (
+		res  []*Object
+		seen = make(map[string]bool)
+	)
+
+	for _, obj := range objects {
+		if obj.IsPrefix || !strings.HasPrefix(obj.URL.Path, prefix) {
+			res = append(res, obj)
+			continue
+		}
+
+		var (
+			rest = strings.TrimPrefix(obj.URL.Path, prefix)
+			idx  = strings.Index(rest, delimiter)
+		)
+
+		if idx < 0 {
+			res = append(res, obj)
+			continue
+		}
+
+		var commonPrefix = prefix + rest[:idx+len(delimiter)]
+
+		if seen[commonPrefix] {
+			continue
+		}
+
+		seen[commonPrefix] = true
+
+		var u url.URL = *obj.URL
+		u.Path = commonPrefix
+
+		res = append(res, &Object{
+			URL:      &u,
+			Metadata: &ObjectMetadata{},
+			IsPrefix: true,
+		})
+	}
+
+	return res
+}
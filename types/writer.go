@@ -4,4 +4,5 @@ import "io"
 
 type Writer interface {
 	io.WriteCloser
+	Abort() error
 }
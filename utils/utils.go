@@ -28,6 +28,7 @@ func CreateObject(ctx context.Context, os *objstr.ObjectStore, u *url.URL, data
 	}
 
 	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		w.Abort()
 		return err
 	}
 